@@ -1,5 +1,11 @@
 package skelington
 
+import (
+	"iter"
+	"math/rand"
+	"strings"
+)
+
 // An interface that handles Skelington allocation by specific strategy.
 type Allocator interface {
 	Tag() string
@@ -7,20 +13,29 @@ type Allocator interface {
 	Allocate(*Skelington, Pather, Pather, string, ErrorHandler) *Skelington
 }
 
-type innerOpenFn func(Pather, Pather, string) (*Level, error)
+// An interface for an Allocator able to yield handles one at a time, rather than
+// allocating all handles to the Skelington at once.
+type StreamAllocator interface {
+	Stream(*Skelington, Pather, Pather, string, ErrorHandler) iter.Seq2[Handle, error]
+}
+
+type innerOpenFn func(Pather, Pather, string, ...ReadOption) (*Level, error)
 
-func openNone(Pather, Pather, string) (*Level, error) {
+func openNone(Pather, Pather, string, ...ReadOption) (*Level, error) {
 	return nil, nil
 }
 
-func openFile(file Pather, root Pather, offset string) (*Level, error) {
-	path := file.Path()
-	return ReadFromFile(path)
+func openFile(file Pather, root Pather, offset string, opts ...ReadOption) (*Level, error) {
+	var path string
+	if file != nil {
+		path = file.Path()
+	}
+	return ReadFromFile(path, opts...)
 }
 
-func openDir(file Pather, root Pather, offset string) (*Level, error) {
+func openDir(file Pather, root Pather, offset string, opts ...ReadOption) (*Level, error) {
 	path := root.Path()
-	return ReadFromDirectory(path, offset)
+	return ReadFromDirectory(path, offset, opts...)
 }
 
 type innerAllocatorFn func(*Skelington, *Level, *Tag, string, ErrorHandler) *Skelington
@@ -29,11 +44,16 @@ type allocator struct {
 	tag string
 	ofn innerOpenFn
 	afn innerAllocatorFn
+	lfn innerAllotFn
 	l   *Level
 }
 
 func newAllocator(tag string, ofn innerOpenFn, afn innerAllocatorFn) Allocator {
-	return &allocator{tag, ofn, afn, nil}
+	return &allocator{tag, ofn, afn, nil, nil}
+}
+
+func newAllotAllocator(tag string, ofn innerOpenFn, lfn innerAllotFn) Allocator {
+	return &allocator{tag, ofn, allot(lfn), lfn, nil}
 }
 
 // A tag for this allocator.
@@ -50,9 +70,11 @@ func (a *allocator) New() Allocator {
 // The primary allocation function of the allocator. Provided two pathers, an offset string
 // and an Errorhandler function, allocates and returns a new Skelington instance.
 func (a *allocator) Allocate(s *Skelington, p Pather, r Pather, offset string, eh ErrorHandler) *Skelington {
-	lv, err := a.ofn(p, r, offset)
+	lv, err := a.ofn(p, r, offset, s.readOptions()...)
 	if err != nil {
-		eh(err)
+		if !s.cancelled() {
+			eh(err)
+		}
 		return nil
 	}
 	a.l = lv
@@ -60,6 +82,62 @@ func (a *allocator) Allocate(s *Skelington, p Pather, r Pather, offset string, e
 	return a.afn(s, a.l, root, offset, eh)
 }
 
+// Streams handles from the allocation one at a time. Each handle is added to the
+// Skelington alone, running hooks and statistics as it is, and is not retained
+// after being yielded. Any error stopping the stream is handled, then yielded
+// with a nil Handle. Allocators without a streaming strategy yield nothing.
+func (a *allocator) Stream(s *Skelington, p Pather, r Pather, offset string, eh ErrorHandler) iter.Seq2[Handle, error] {
+	return func(yield func(Handle, error) bool) {
+		if a.lfn == nil {
+			return
+		}
+		var stopped bool
+		fail := func(err error) {
+			if !s.cancelled() {
+				eh(err)
+				if !stopped {
+					yield(nil, err)
+				}
+			}
+		}
+		lv, err := a.ofn(p, r, offset, s.readOptions()...)
+		if err != nil {
+			fail(err)
+			return
+		}
+		a.l = lv
+		al, err := a.lfn(s, a.l, offset)
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		if err = s.RunHook(HBefore); err != nil {
+			fail(err)
+			return
+		}
+		units := make(map[string]int)
+		for nh := range sequenced(s, r.GetTag(), al) {
+			s.Clear()
+			if err = s.Add(nh); err != nil {
+				fail(err)
+				return
+			}
+			units[strings.ToUpper(nh.Unit().Value)]++
+			if stopped = !yield(nh, nil); stopped {
+				break
+			}
+		}
+		s.Clear()
+		for tag, n := range units {
+			s.Report()[tag] = s.Report()[tag] + n
+		}
+		if err = s.RunHook(HAfter); err != nil {
+			fail(err)
+		}
+	}
+}
+
 func isOffset(o string, z *Level) *Level {
 	if o != "" {
 		if nz := offset(z, o); nz != nil {
@@ -69,7 +147,7 @@ func isOffset(o string, z *Level) *Level {
 	return z
 }
 
-func enumerate(lv *Level, from int) error {
+func proportion(lv *Level, from int) {
 	var numRelative int
 
 	for _, level := range lv.Levels {
@@ -95,13 +173,36 @@ func enumerate(lv *Level, from int) error {
 			}
 		}
 	}
+}
 
-	for _, level := range lv.Levels {
-		level.Actual = int(float64(from) * level.Percent)
+func enumerate(lv *Level, from int, r Rounding) error {
+	if lv.Rounding != "" {
+		var err error
+		r, err = ParseRounding(lv.Rounding)
+		if err != nil {
+			return err
+		}
+	}
+
+	proportion(lv, from)
+
+	p := make([]float64, len(lv.Levels))
+	for i, level := range lv.Levels {
+		p[i] = level.Percent
+	}
+	for i, n := range r.apportion(from, p) {
+		lv.Levels[i].Actual = n
+	}
+	err := constrain(lv)
+	if err != nil {
+		return err
+	}
 
-		err := enumerate(
+	for _, level := range lv.Levels {
+		err = enumerate(
 			level,
 			level.Actual,
+			r,
 		)
 		if err != nil {
 			return err
@@ -110,81 +211,230 @@ func enumerate(lv *Level, from int) error {
 	return nil
 }
 
+func sample(lv *Level, from int, r *rand.Rand) error {
+	proportion(lv, from)
+
+	p := make([]float64, len(lv.Levels))
+	for i, level := range lv.Levels {
+		p[i] = level.Percent
+	}
+	for i, n := range multinomial(from, p, r) {
+		lv.Levels[i].Actual = n
+	}
+	err := constrain(lv)
+	if err != nil {
+		return err
+	}
+
+	for _, level := range lv.Levels {
+		err = sample(level, level.Actual, r)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Draws n times from the provided probabilities, returning the number of draws
+// for each. Probabilities summing to less than one leave draws unassigned, and
+// probabilities summing to more than one are scaled down.
+func multinomial(n int, p []float64, r *rand.Rand) []int {
+	ret := make([]int, len(p))
+	var total float64
+	for _, v := range p {
+		total = total + v
+	}
+	scale := 1.0
+	if total > 1 {
+		scale = total
+	}
+	for i := 0; i < n; i++ {
+		x := r.Float64() * scale
+		for j, v := range p {
+			if x < v {
+				ret[j] = ret[j] + 1
+				break
+			}
+			x = x - v
+		}
+	}
+	return ret
+}
+
 // An empty allocation, i.e. returns a skeleton with nothing.
 func empAllocate(s *Skelington, z *Level, root *Tag, offset string, eh ErrorHandler) *Skelington {
 	return s
 }
 
-//wsiwyg allocator, no calc, no branch, nothing just turn file to handles
+// A number of handles to allocate for a Level.
+type allotment struct {
+	lv *Level
+	n  int
+}
+
+type innerAllotFn func(*Skelington, *Level, string) ([]allotment, error)
+
+func allot(lfn innerAllotFn) innerAllocatorFn {
+	return func(s *Skelington, z *Level, root *Tag, offset string, eh ErrorHandler) *Skelington {
+		fail := func(err error) *Skelington {
+			if !s.cancelled() {
+				eh(err)
+			}
+			return nil
+		}
+		al, err := lfn(s, z, offset)
+		if err != nil {
+			return fail(err)
+		}
+
+		s.AddHook(HPost, SkelingtonSequence)
+		if err = s.RunHook(HBefore); err != nil {
+			return fail(err)
+		}
+		add := make([]Handle, 0)
+		for nh := range sequenced(s, root, al) {
+			add = append(add, nh)
+		}
+		if s.cancelled() {
+			return nil
+		}
+		if err = s.Add(add...); err != nil {
+			return fail(err)
+		}
+		if err = s.RunHook(HAfter); err != nil {
+			return fail(err)
+		}
+		return s
+	}
+}
+
+// Yields handles for every allotment in turn, already sequenced by unit, until
+// the context of the Skelington is done.
+func sequenced(s *Skelington, root *Tag, al []allotment) iter.Seq[Handle] {
+	count := make(map[string]int)
+	for _, a := range al {
+		if a.n > 0 {
+			u := a.lv.Unit()
+			count[u.Value] = count[u.Value] + a.n
+		}
+	}
+	return func(yield func(Handle) bool) {
+		at := make(map[string]int)
+		for _, a := range al {
+			family, unit, attributes := a.lv.Family(), a.lv.Unit(), a.lv.attributes()
+			for i := 1; i <= a.n; i++ {
+				if s.cancelled() {
+					return
+				}
+				at[unit.Value] = at[unit.Value] + 1
+				seq := &Sequence{at[unit.Value], count[unit.Value]}
+				if !yield(newHandle(seq, root, family, unit, attributes)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// A literal, what you see is what you get allocation. Every leaf in the
+// provided Level becomes handles as written, Number copies each, without any
+// proportional calculation or branching.
+func litAllot(s *Skelington, z *Level, offset string) ([]allotment, error) {
+	z = isOffset(offset, z)
+
+	var ret []allotment
+	for _, lv := range flatten(z) {
+		ret = append(ret, allotment{lv, lv.Number})
+	}
+	return ret, nil
+}
 
 // A continually reallocating shrinking proportion allocation. Attempts to
 // allocate handles by proportion of handles remaining to allocate.
-func rspAllocate(s *Skelington, z *Level, root *Tag, offset string, eh ErrorHandler) *Skelington {
+func rspAllot(s *Skelington, z *Level, offset string) ([]allotment, error) {
 	z = isOffset(offset, z)
 
-	err := enumerate(z, z.Number)
+	err := enumerate(z, z.bound(z.Number), s.rounding())
 	if err != nil {
-		eh(err)
-		return nil
+		return nil, err
 	}
 
-	s.AddHook(HPost, SkelingtonSequence)
-	s.RunHook(HBefore)
-	add := make([]Handle, 0)
+	var ret []allotment
 	for _, lv := range flatten(z) {
-		for i := 1; i <= lv.Actual; i++ {
-			nh := newHandle(lv.sequence, root, lv.Family(), lv.Unit())
-			add = append(add, nh)
-		}
+		ret = append(ret, allotment{lv, lv.Actual})
 	}
-	s.Add(add...)
-	s.RunHook(HAfter)
-	return s
+	return ret, nil
 }
 
-// A branching expansion allocation. Branches expand from a root to create handles
-// as directed and necessary.
-func bgeAllocate(s *Skelington, z *Level, root *Tag, offset string, eh ErrorHandler) *Skelington {
+// A seeded stochastic proportion allocation. Treats proportions as probabilities,
+// drawing the number of handles for each level from its parent, reproducibly for
+// the seed provided to the Processor with SetSeed.
+func sspAllot(s *Skelington, z *Level, offset string) ([]allotment, error) {
 	z = isOffset(offset, z)
 
-	z.Iter(branch)
+	seed := s.Seed()
+	s.Report()[SeedStatistic] = int(seed)
+	err := sample(z, z.bound(z.Number), rand.New(rand.NewSource(seed)))
+	if err != nil {
+		return nil, err
+	}
 
-	s.AddHook(HPost, SkelingtonSequence)
-	s.RunHook(HBefore)
-	add := make([]Handle, 0)
-	fn := func(lv *Level) {
+	var ret []allotment
+	for _, lv := range flatten(z) {
+		ret = append(ret, allotment{lv, lv.Actual})
+	}
+	return ret, nil
+}
+
+// A branching expansion allocation. Branches expand from a root to create handles
+// as directed and necessary. Every leaf below the root branches into Number
+// copies of itself and everything below it, which is counted rather than cloned.
+// Any min, max or exact constraint bounds the copies branched for each leaf, while
+// a level that is not a leaf may only be excluded, with an exact constraint of 0.
+func bgeAllot(s *Skelington, z *Level, offset string) ([]allotment, error) {
+	z = isOffset(offset, z)
+
+	var ret []allotment
+	var expand func(*Level, int) error
+	expand = func(lv *Level, n int) error {
 		if isLeaf(lv) {
-			nh := newHandle(lv.sequence, root, lv.Family(), lv.Unit())
-			add = append(add, nh)
+			ret = append(ret, allotment{lv, n})
 		}
+		for _, c := range lv.Levels {
+			if c.Exact != nil && *c.Exact == 0 {
+				continue
+			}
+			if err := c.satisfiable(); err != nil {
+				return err
+			}
+			if !isLeaf(c) && c.constrained() {
+				return ConstraintError(levelPath(c), "only a leaf may be bounded by branching expansion")
+			}
+			cn := n
+			if copies := c.bound(c.Number); isLeaf(c) && copies > 1 {
+				cn = n * copies
+			}
+			if err := expand(c, cn); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
-	z.Iter(fn)
-	s.Add(add...)
-	s.RunHook(HAfter)
-
-	return s
+	if err := expand(z, 1); err != nil {
+		return nil, err
+	}
+	return ret, nil
 }
 
 // An allocation derived an existing directory of files.
-func edfAllocate(s *Skelington, z *Level, root *Tag, offset string, eh ErrorHandler) *Skelington {
-	toAdd := make(map[*Level]int)
-	add := make([]Handle, 0)
-	s.AddHook(HPost, SkelingtonSequence)
-	s.RunHook(HBefore)
+func edfAllot(s *Skelington, z *Level, offset string) ([]allotment, error) {
+	var ret []allotment
 	z.Iter(func(iv *Level) {
 		if iv.Number > 0 {
-			toAdd[iv] = iv.Number - 1
+			ret = append(ret, allotment{iv, iv.Number})
 		}
 	})
-	for k, v := range toAdd {
-		for i := 0; i <= v; i = i + 1 {
-			nh := newHandle(k.sequence, root, k.Family(), k.Unit())
-			add = append(add, nh)
-		}
-	}
-	s.Add(add...)
-	s.RunHook(HAfter)
-	return s
+	return ret, nil
 }
 
 type allocators struct {
@@ -207,7 +457,9 @@ func (a *allocators) Set(c Allocator) {
 // A struct maintaining available allocators.
 // Package defaults provide the following allocators:
 // emp - only provides an empty Skelington instance for further use.
+// lit - literal, what you see is what you get
 // rsp - reallocating shrinking proportion
+// ssp - seeded stochastic proportion
 // bge - branching expansion
 // edf - existing directory of files
 var Allocators *allocators
@@ -215,7 +467,9 @@ var Allocators *allocators
 func init() {
 	Allocators = &allocators{make(map[string]Allocator, 0)}
 	Allocators.Set(newAllocator("emp", openNone, empAllocate))
-	Allocators.Set(newAllocator("rsp", openFile, rspAllocate))
-	Allocators.Set(newAllocator("bge", openFile, bgeAllocate))
-	Allocators.Set(newAllocator("edf", openDir, edfAllocate))
+	Allocators.Set(newAllotAllocator("lit", openFile, litAllot))
+	Allocators.Set(newAllotAllocator("rsp", openFile, rspAllot))
+	Allocators.Set(newAllotAllocator("ssp", openFile, sspAllot))
+	Allocators.Set(newAllotAllocator("bge", openFile, bgeAllot))
+	Allocators.Set(newAllotAllocator("edf", openDir, edfAllot))
 }
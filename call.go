@@ -0,0 +1,157 @@
+package skelington
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// A type for specifying which handles are kept in order when called concurrently.
+type CallOrdering int
+
+const (
+	Unordered     CallOrdering = iota // handles are called in any order
+	FamilyOrdered                     // handles sharing a family are called in order
+	UnitOrdered                       // handles sharing a family and unit are called in order
+)
+
+// An error returned by the Call function of a specific Handle.
+type CallError struct {
+	Handle Handle
+	Err    error
+}
+
+// The string value of the CallError.
+func (c *CallError) Error() string {
+	return fmt.Sprintf("%s: %s", c.Handle.Path(), c.Err)
+}
+
+// Returns the underlying error.
+func (c *CallError) Unwrap() error {
+	return c.Err
+}
+
+// An array of CallError, in the order of the handles they were returned from.
+type CallErrors []*CallError
+
+// The string value of all CallError, one per line.
+func (c CallErrors) Error() string {
+	var ret []string
+	for _, v := range c {
+		ret = append(ret, v.Error())
+	}
+	return strings.Join(ret, "\n")
+}
+
+// Returns all underlying errors.
+func (c CallErrors) Unwrap() []error {
+	var ret []error
+	for _, v := range c {
+		ret = append(ret, v)
+	}
+	return ret
+}
+
+func ordering(h Handle, o CallOrdering) string {
+	var tags TagSort
+	tags = append(tags, h.Family()...)
+	if o == UnitOrdered {
+		tags = append(tags, h.Unit())
+	}
+	tags.Sort()
+	return strings.Join(tags.List(), "/")
+}
+
+func group(hs []Handle, o CallOrdering) [][]int {
+	var ret [][]int
+	if o == Unordered {
+		for i := range hs {
+			ret = append(ret, []int{i})
+		}
+		return ret
+	}
+	idx := make(map[string]int)
+	for i, h := range hs {
+		k := ordering(h, o)
+		g, ok := idx[k]
+		if !ok {
+			g = len(ret)
+			idx[k] = g
+			ret = append(ret, nil)
+		}
+		ret[g] = append(ret[g], i)
+	}
+	return ret
+}
+
+// Runs the Call function of every provided handle across the provided number of
+// workers (defaulting to GOMAXPROCS when less than one), keeping handles in order
+// as directed by the CallOrdering. Every error is gathered and returned as
+// CallErrors, ordered as the provided handles.
+func CallConcurrent(hs []Handle, workers int, o CallOrdering) error {
+	return CallConcurrentContext(context.Background(), hs, workers, o)
+}
+
+// Runs handle calls as CallConcurrent, no longer calling handles once the
+// provided context is done and returning the context error.
+func CallConcurrentContext(ctx context.Context, hs []Handle, workers int, o CallOrdering) error {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	errs := make([]error, len(hs))
+	groups := make(chan []int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for g := range groups {
+				for _, i := range g {
+					if ctx.Err() != nil {
+						break
+					}
+					errs[i] = hs[i].Call()
+				}
+			}
+		}()
+	}
+	for _, g := range group(hs, o) {
+		if ctx.Err() != nil {
+			break
+		}
+		groups <- g
+	}
+	close(groups)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var ret CallErrors
+	for i, err := range errs {
+		if err != nil {
+			ret = append(ret, &CallError{hs[i], err})
+		}
+	}
+	if len(ret) > 0 {
+		return ret
+	}
+	return nil
+}
+
+// Sets a hook that sets HandleFunc per handle across the entire skeleton, and
+// executing the Call function for all handles concurrently in the post add hook.
+// Provided a View, only handles of the View are set and called.
+func SkelingtonConcurrentHandleCalls(s HandleSet, workers int, o CallOrdering, hf ...HandleCall) {
+	for _, h := range s.Handles() {
+		h.SetCall(hf...)
+	}
+	s.AddHook(
+		HPost,
+		func(*Skelington) error {
+			return CallConcurrentContext(s.Context(), s.Handles(), workers, o)
+		},
+	)
+}
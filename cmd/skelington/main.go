@@ -0,0 +1,64 @@
+// A command line tool for working with skelington specifications.
+//
+// Usage:
+//
+//	skelington validate [-format auto|yaml|json|toml] file...
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Laughs-In-Flowers/skelington"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: skelington validate [-format auto|yaml|json|toml] file...\n")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "validate":
+		os.Exit(validate(os.Args[2:]))
+	default:
+		usage()
+	}
+}
+
+func validate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	format := fs.String("format", "auto", "specification format: auto, yaml, json or toml")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		usage()
+	}
+
+	f, err := skelington.ParseSpecFormat(*format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 2
+	}
+
+	ret := 0
+	for _, path := range fs.Args() {
+		err = skelington.ValidateFile(path, skelington.ReadFormat(f))
+		var se skelington.SpecErrors
+		switch {
+		case errors.As(err, &se):
+			for _, e := range se {
+				fmt.Fprintf(os.Stdout, "%s\n", e)
+			}
+			ret = 1
+		case err != nil:
+			fmt.Fprintf(os.Stdout, "%s: %s\n", path, err)
+			ret = 1
+		}
+	}
+	return ret
+}
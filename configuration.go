@@ -1,7 +1,10 @@
 package skelington
 
 import (
+	"io"
+	"io/fs"
 	"sort"
+	"time"
 
 	"github.com/Laughs-In-Flowers/xrr"
 )
@@ -117,6 +120,7 @@ var builtIns = []Config{
 	config{1001, sRoot},
 	config{1002, sError},
 	config{1003, sAllocator},
+	config{1004, sSeed},
 }
 
 var ConfigurationError = xrr.Xrror("configuration error: %s").Out
@@ -155,6 +159,31 @@ func sError(p *Processor) error {
 	return nil
 }
 
+// Sets a reader to read a specification from, if the allocator requires one.
+// The reader is read in full during configuration, so that the specification
+// remains available for reallocation.
+func SetFileReader(r io.Reader) Config {
+	return DefaultConfig(
+		func(p *Processor) error {
+			b, err := io.ReadAll(r)
+			if err != nil {
+				return ConfigurationError(err)
+			}
+			p.readOptions = append(p.readOptions, readRaw(b))
+			return nil
+		})
+}
+
+// Sets a file system that any specification file or directory is read from,
+// instead of the operating system.
+func SetFS(fsys fs.FS) Config {
+	return DefaultConfig(
+		func(p *Processor) error {
+			p.readOptions = append(p.readOptions, ReadFS(fsys))
+			return nil
+		})
+}
+
 // Sets the skelington error handling method by string key:
 // one of 'continue', 'exit', or 'panic' with the default being 'continue'.
 func SetError(err string) Config {
@@ -188,6 +217,16 @@ func SetHook(t HookTiming, h ...SkelingtonHook) Config {
 		})
 }
 
+// Sets any number of SkelingtonHookContext for the provided HookTiming, each
+// provided the context of the Skelington instance when run.
+func SetHookContext(t HookTiming, h ...SkelingtonHookContext) Config {
+	var l []SkelingtonHook
+	for _, fn := range h {
+		l = append(l, HookContext(fn))
+	}
+	return SetHook(t, l...)
+}
+
 //
 func SetStat(k string, fn ...StatFunc) Config {
 	return DefaultConfig(
@@ -230,3 +269,98 @@ func SetAllocationOffset(o string) Config {
 			return nil
 		})
 }
+
+// Sets the format of any specification file read by the allocator by string key:
+// one of 'auto', 'yaml', 'json', or 'toml' with the default being 'auto', i.e.
+// detected by file extension or content.
+func SetSpecFormat(k string) Config {
+	return DefaultConfig(
+		func(p *Processor) error {
+			f, err := ParseSpecFormat(k)
+			if err != nil {
+				return ConfigurationError(err)
+			}
+			p.readOptions = append(p.readOptions, ReadFormat(f))
+			return nil
+		})
+}
+
+func sSeed(p *Processor) error {
+	if !p.seeded {
+		p.seed = time.Now().UnixNano()
+		p.seeded = true
+	}
+	return nil
+}
+
+// Sets the seed used by any stochastic allocator, the default being drawn from the
+// current time. The seed used is reported by statistics under SeedStatistic, and
+// returned by the Skelington Seed function.
+func SetSeed(seed int64) Config {
+	return DefaultConfig(
+		func(p *Processor) error {
+			p.seed = seed
+			p.seeded = true
+			return nil
+		})
+}
+
+// Sets how proportional allocators round counts to whole handles by string key:
+// one of 'floor', 'nearest', 'hamilton' (or 'largest-remainder'), or 'preserve'
+// with the default being 'floor'. A rounding set on any Level of a specification
+// takes precedence for that Level and below.
+func SetRounding(k string) Config {
+	return DefaultConfig(
+		func(p *Processor) error {
+			r, err := ParseRounding(k)
+			if err != nil {
+				return ConfigurationError(err)
+			}
+			p.rounding = r
+			return nil
+		})
+}
+
+// Sets validation of any specification read by the allocator, stopping allocation
+// with SpecErrors, as returned by New, for an invalid specification.
+func SetValidation() Config {
+	return DefaultConfig(
+		func(p *Processor) error {
+			p.readOptions = append(p.readOptions, ReadValidate())
+			return nil
+		})
+}
+
+// Sets variables used to render placeholders, e.g. '${cars}', in any
+// specification read by the allocator. See ReadVariables.
+func SetVariables(v map[string]string) Config {
+	return DefaultConfig(
+		func(p *Processor) error {
+			p.readOptions = append(p.readOptions, ReadVariables(v))
+			return nil
+		})
+}
+
+// Sets an ItemFactory providing an item for every allocated handle without one.
+func SetItemFactory(fn ItemFactory) Config {
+	return DefaultConfig(
+		func(p *Processor) error {
+			p.itemFactory = fn
+			return nil
+		})
+}
+
+// Sets the SequenceFormat of every allocated handle, used for the sequence of
+// its path and, by the edf allocator without an offset, to recognise sequences.
+// The default being DefaultSequence. See ParseSequenceFormat.
+func SetSequenceFormat(f SequenceFormat) Config {
+	return DefaultConfig(
+		func(p *Processor) error {
+			if f == nil {
+				return ConfigurationError("nil sequence format")
+			}
+			p.sequenceFormat = f
+			p.readOptions = append(p.readOptions, ReadSequenceFormat(f))
+			return nil
+		})
+}
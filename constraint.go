@@ -0,0 +1,118 @@
+package skelington
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Laughs-In-Flowers/xrr"
+)
+
+var ConstraintError = xrr.Xrror("unsatisfiable constraints for %s: %s").Out
+
+func (lv *Level) constrained() bool {
+	return lv.Exact != nil || lv.Min > 0 || lv.Max > 0
+}
+
+// Returns the lower and upper bound of the Level, with an upper bound below zero
+// being unbounded.
+func (lv *Level) bounds() (int, int) {
+	if lv.Exact != nil {
+		return *lv.Exact, *lv.Exact
+	}
+	if lv.Max > 0 {
+		return lv.Min, lv.Max
+	}
+	return lv.Min, -1
+}
+
+// Returns a ConstraintError if the lower bound of the Level exceeds its upper bound.
+func (lv *Level) satisfiable() error {
+	if lo, hi := lv.bounds(); hi >= 0 && lo > hi {
+		return ConstraintError(levelPath(lv), fmt.Sprintf("min %d exceeds max %d", lo, hi))
+	}
+	return nil
+}
+
+func (lv *Level) bound(n int) int {
+	lo, hi := lv.bounds()
+	if n < lo {
+		n = lo
+	}
+	if hi >= 0 && n > hi {
+		n = hi
+	}
+	return n
+}
+
+// Applies any min, max or exact constraint to the Actual count of every child
+// Level, redistributing any surplus or deficit among siblings with room to spare,
+// largest proportion first, so the children keep the same total.
+func constrain(lv *Level) error {
+	var constrained bool
+	for _, c := range lv.Levels {
+		if !c.constrained() {
+			continue
+		}
+		constrained = true
+		if err := c.satisfiable(); err != nil {
+			return err
+		}
+	}
+	if !constrained {
+		return nil
+	}
+
+	var total, low, high int
+	var unbounded bool
+	for _, c := range lv.Levels {
+		if c.Actual > 0 {
+			total = total + c.Actual
+		}
+		lo, hi := c.bounds()
+		low = low + lo
+		if hi < 0 {
+			unbounded = true
+		}
+		high = high + hi
+	}
+	if low > total {
+		return ConstraintError(levelPath(lv), fmt.Sprintf("children require at least %d of %d", low, total))
+	}
+	if !unbounded && high < total {
+		return ConstraintError(levelPath(lv), fmt.Sprintf("children allow at most %d of %d", high, total))
+	}
+
+	var sum int
+	for _, c := range lv.Levels {
+		c.Actual = c.bound(c.Actual)
+		sum = sum + c.Actual
+	}
+
+	order := make([]*Level, len(lv.Levels))
+	copy(order, lv.Levels)
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i].Percent > order[j].Percent
+	})
+	for delta := total - sum; delta != 0; {
+		for _, c := range order {
+			lo, hi := c.bounds()
+			switch {
+			case delta > 0 && (hi < 0 || c.Actual < hi):
+				c.Actual = c.Actual + 1
+				delta = delta - 1
+			case delta < 0 && c.Actual > lo:
+				c.Actual = c.Actual - 1
+				delta = delta + 1
+			}
+			if delta == 0 {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func levelPath(lv *Level) string {
+	return strings.Join(tagged(lv), "/")
+}
@@ -0,0 +1,185 @@
+package skelington
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// A handle matched between two Skelington instances with differing sequences.
+type Resequence struct {
+	From, To Handle
+}
+
+// The difference between two Skelington instances.
+type Difference struct {
+	Added       []Handle       // handles of the second instance without a match
+	Removed     []Handle       // handles of the first instance without a match
+	Resequenced []Resequence   // matched handles with differing sequences
+	Tags        map[string]int // nonzero count changes by reported statistic key, but for SeedStatistic
+}
+
+// Returns the family of the handle relative to its root, without any empty tag
+// or any leading tag naming the root itself, as given to handles read from a
+// directory by the edf allocator.
+func relativeFamily(h Handle) []string {
+	root := path.Clean(filepath.ToSlash(h.Root().Value))
+	tags := append(TagSort{}, h.Family()...)
+	tags.Sort()
+	var ret []string
+	for _, t := range tags {
+		if t.Value == "" {
+			continue
+		}
+		if len(ret) == 0 && path.Clean(filepath.ToSlash(t.Value)) == root {
+			continue
+		}
+		ret = append(ret, t.Value)
+	}
+	return ret
+}
+
+func relativeKey(h Handle) string {
+	return strings.Join(append(relativeFamily(h), h.Unit().Value, formatSequence(h)), "/")
+}
+
+func sequenceKey(h Handle) string {
+	var n int
+	if s := h.Sequence(); s != nil {
+		n = s.Number
+	}
+	return fmt.Sprintf("%s/%s#%d", strings.Join(relativeFamily(h), "/"), h.Unit().Value, n)
+}
+
+// Returns the Difference of b from a. Handles are matched by path relative to
+// their root, then any remaining by family, unit and sequence number, reporting
+// those whose sequences differ as resequenced, e.g. '2-of-3' having become
+// '2-of-4'. A Skelington allocated from a specification may so be compared with
+// one read from its materialized directory by the edf allocator.
+func Diff(a, b *Skelington) *Difference {
+	d := &Difference{Tags: make(map[string]int)}
+
+	paths := make(map[string]Handle)
+	for _, h := range a.Has {
+		paths[relativeKey(h)] = h
+	}
+	var unmatched []Handle
+	for _, h := range b.Has {
+		if _, ok := paths[relativeKey(h)]; ok {
+			delete(paths, relativeKey(h))
+			continue
+		}
+		unmatched = append(unmatched, h)
+	}
+
+	keys := make(map[string]Handle)
+	for _, h := range a.Has {
+		if _, ok := paths[relativeKey(h)]; ok {
+			keys[sequenceKey(h)] = h
+		}
+	}
+	for _, h := range unmatched {
+		k := sequenceKey(h)
+		from, ok := keys[k]
+		if !ok {
+			d.Added = append(d.Added, h)
+			continue
+		}
+		delete(keys, k)
+		delete(paths, relativeKey(from))
+		if from.Sequence().String() != h.Sequence().String() {
+			d.Resequenced = append(d.Resequenced, Resequence{from, h})
+		}
+	}
+	for _, h := range a.Has {
+		if _, ok := paths[relativeKey(h)]; ok {
+			d.Removed = append(d.Removed, h)
+		}
+	}
+
+	ar, br := a.Report(), b.Report()
+	for k, v := range br {
+		d.Tags[k] = v - ar[k]
+	}
+	for k, v := range ar {
+		if _, ok := br[k]; !ok {
+			d.Tags[k] = -v
+		}
+	}
+	for k, v := range d.Tags {
+		if v == 0 || k == SeedStatistic {
+			delete(d.Tags, k)
+		}
+	}
+	return d
+}
+
+// Returns true when there is no difference.
+func (d *Difference) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Resequenced) == 0 && len(d.Tags) == 0
+}
+
+func (d *Difference) tags() []string {
+	var ret []string
+	for k := range d.Tags {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// The string value of the Difference, one line per change: '+' for added, '-'
+// for removed and '~' for resequenced handles, followed by each count change.
+func (d *Difference) String() string {
+	var b strings.Builder
+	for _, h := range d.Added {
+		fmt.Fprintf(&b, "+ %s\n", h.Path())
+	}
+	for _, h := range d.Removed {
+		fmt.Fprintf(&b, "- %s\n", h.Path())
+	}
+	for _, r := range d.Resequenced {
+		fmt.Fprintf(&b, "~ %s -> %s\n", r.From.Path(), r.To.Path())
+	}
+	for _, k := range d.tags() {
+		fmt.Fprintf(&b, "%s %+d\n", k, d.Tags[k])
+	}
+	return b.String()
+}
+
+type jsonResequence struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type jsonDifference struct {
+	Added       []string         `json:"added"`
+	Removed     []string         `json:"removed"`
+	Resequenced []jsonResequence `json:"resequenced"`
+	Tags        map[string]int   `json:"tags"`
+}
+
+func handlePaths(hs []Handle) []string {
+	ret := make([]string, 0, len(hs))
+	for _, h := range hs {
+		ret = append(ret, h.Path())
+	}
+	return ret
+}
+
+// Returns the Difference as json, with handles given by path.
+func (d *Difference) MarshalJSON() ([]byte, error) {
+	jd := jsonDifference{
+		Added:       handlePaths(d.Added),
+		Removed:     handlePaths(d.Removed),
+		Resequenced: make([]jsonResequence, 0, len(d.Resequenced)),
+		Tags:        d.Tags,
+	}
+	for _, r := range d.Resequenced {
+		jd.Resequenced = append(jd.Resequenced, jsonResequence{r.From.Path(), r.To.Path()})
+	}
+	return json.Marshal(jd)
+}
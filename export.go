@@ -0,0 +1,96 @@
+package skelington
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// Provided a Skelington, gathers all handles by Family and Unit into a new Level
+// with a count of handles for each unit in Number.
+func LevelFromSkelington(s *Skelington) *Level {
+	top := emptyLevel("")
+	for _, h := range s.Has {
+		lv := top
+		tags := append(TagSort{}, h.Family()...)
+		tags.Sort()
+		for _, t := range append(tags, h.Unit()) {
+			lv = child(lv, t.Value)
+		}
+		lv.Number = lv.Number + 1
+		if a := attributesOf(h); lv.Attributes == nil && len(a) > 0 {
+			lv.Attributes = a
+		}
+	}
+	top.Iter(func(lv *Level) {
+		sort.SliceStable(lv.Levels, func(i, j int) bool {
+			return lv.Levels[i].Tag < lv.Levels[j].Tag
+		})
+	})
+	ret := top
+	if len(top.Levels) == 1 {
+		ret = top.Levels[0]
+		ret.parent = nil
+	}
+	notate(ret, ret.Levels, 0)
+	return ret
+}
+
+func child(lv *Level, tag string) *Level {
+	for _, c := range lv.Levels {
+		if c.Tag == tag {
+			return c
+		}
+	}
+	c := emptyLevel(tag)
+	c.parent = lv
+	lv.Levels = append(lv.Levels, c)
+	return c
+}
+
+func exportable(lv *Level) *Level {
+	ret := emptyLevel(lv.Tag)
+	ret.Number = lv.Number
+	ret.Attributes = lv.Attributes
+	for _, c := range lv.Levels {
+		ret.Levels = append(ret.Levels, exportable(c))
+	}
+	if ret.Number > 0 && len(ret.Levels) > 0 {
+		ret.Leaf = true
+	}
+	return ret
+}
+
+// Writes the provided Level to the io.Writer as a yaml specification readable by
+// ReadFromFile. Only tags, counts and attributes are written, with any Level both
+// holding a count and children marked as a leaf.
+func WriteLevel(w io.Writer, lv *Level) error {
+	b, err := yaml.Marshal(exportable(lv))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// Writes the provided Level to a yaml specification file at the provided path.
+func WriteToFile(path string, lv *Level) error {
+	exist(filepath.Dir(path))
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = WriteLevel(f, lv)
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
+	return err
+}
+
+// Writes the provided Skelington to the io.Writer as a yaml specification.
+func ExportSkelington(w io.Writer, s *Skelington) error {
+	return WriteLevel(w, LevelFromSkelington(s))
+}
@@ -1,6 +1,7 @@
 package skelington
 
 import (
+	"context"
 	"path/filepath"
 	"sort"
 )
@@ -31,6 +32,9 @@ type Tagger interface {
 // A function taking a Handle and returning an error.
 type HandleCall func(Handle) error
 
+// A function taking a context and a Handle and returning an error.
+type HandleCallContext func(context.Context, Handle) error
+
 // An interface for managing HandleFunc.
 type Caller interface {
 	Call() error
@@ -44,17 +48,35 @@ type Handles interface {
 	SetItem(interface{})
 }
 
-type handle struct {
-	id       string
-	sequence *Sequence
-	root     *Tag
-	family   []*Tag
-	unit     *Tag
-	calls    []HandleCall
-	item     interface{}
+// An interface for reading metadata attributes of the handle, as inherited from
+// the specification Level it was allocated from. Every Handle allocated by a
+// Skelington is an Attributer.
+type Attributer interface {
+	Attributes() map[string]interface{}
+	Attr(string) (interface{}, bool)
+}
+
+// Returns the attributes of the Handle if it is an Attributer, or nil.
+func attributesOf(h Handle) map[string]interface{} {
+	if a, ok := h.(Attributer); ok {
+		return a.Attributes()
+	}
+	return nil
 }
 
-func newHandle(s *Sequence, root *Tag, family []*Tag, unit *Tag) *handle {
+type handle struct {
+	id         string
+	sequence   *Sequence
+	root       *Tag
+	family     []*Tag
+	unit       *Tag
+	calls      []HandleCall
+	item       interface{}
+	attributes map[string]interface{}
+	format     SequenceFormat
+}
+
+func newHandle(s *Sequence, root *Tag, family []*Tag, unit *Tag, attributes map[string]interface{}) *handle {
 	h := &handle{
 		uuidString(),
 		s,
@@ -63,6 +85,8 @@ func newHandle(s *Sequence, root *Tag, family []*Tag, unit *Tag) *handle {
 		unit,
 		make([]HandleCall, 0),
 		nil,
+		attributes,
+		nil,
 	}
 	return h
 }
@@ -116,8 +140,7 @@ func (h *handle) Tagged(seq bool) TagSort {
 	u := h.Unit()
 	ret = append(ret, u)
 	if seq {
-		s := h.Sequence()
-		ret = append(ret, &Tag{u.Order + 1, s.String()})
+		ret = append(ret, &Tag{u.Order + 1, formatSequence(h)})
 	}
 	ret.Sort()
 	return ret
@@ -176,3 +199,15 @@ func (h *handle) Item() interface{} {
 func (h *handle) SetItem(i interface{}) {
 	h.item = i
 }
+
+// Returns all attributes of the handle. The returned map is shared between
+// handles of the same Level and must not be modified.
+func (h *handle) Attributes() map[string]interface{} {
+	return h.attributes
+}
+
+// Returns the attribute of the provided key, and whether it was found.
+func (h *handle) Attr(k string) (interface{}, bool) {
+	v, ok := h.attributes[k]
+	return v, ok
+}
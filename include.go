@@ -0,0 +1,141 @@
+package skelington
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Laughs-In-Flowers/xrr"
+)
+
+var IncludeError = xrr.Xrror("unable to include %s: %s").Out
+
+// Returns any reference to another specification, or section of one, made by the
+// Level with either 'include' or '$ref'. Any other value of the Level overrides
+// that of the included Level, as for prototypes.
+//
+// A reference is a file path relative to the including file, optionally followed
+// by '#' and a tag path naming a section within it, e.g. 'parts.yaml',
+// 'parts.yaml#PowerUps/Laser', or '#Laser' for a section of the including file.
+func (lv *Level) reference() string {
+	if lv.Include != "" {
+		return lv.Include
+	}
+	return lv.Ref
+}
+
+func (rd *reading) resolve(doc, lv *Level, file string, stack []string) (*Level, error) {
+	if ref := lv.reference(); ref != "" {
+		inc, err := rd.include(doc, file, ref, stack)
+		if err != nil {
+			return nil, err
+		}
+		return rd.overlaid(doc, inc, lv, file, stack)
+	}
+	if lv.Use != "" {
+		return rd.prototype(doc, lv, file, stack)
+	}
+	for i, c := range lv.Levels {
+		nc, err := rd.resolve(doc, c, file, stack)
+		if err != nil {
+			return nil, err
+		}
+		lv.Levels[i] = nc
+	}
+	return lv, nil
+}
+
+func (rd *reading) include(doc *Level, file, ref string, stack []string) (*Level, error) {
+	target, section, _ := strings.Cut(ref, "#")
+	ifile := file
+	if target != "" {
+		ifile = rd.relative(file, target)
+	}
+	key := ifile + "#" + section
+	for _, k := range stack {
+		if k == key {
+			return nil, IncludeError(ref, "cycle through "+strings.Join(append(stack, key), " -> "))
+		}
+	}
+
+	idoc := doc
+	if target != "" {
+		raw, err := rd.included(ifile)
+		if err != nil {
+			return nil, IncludeError(ref, err)
+		}
+		inc := *rd
+		inc.raw = nil
+		idoc, err = inc.document(ifile, raw)
+		if err != nil {
+			return nil, IncludeError(ref, err)
+		}
+	}
+
+	found := idoc
+	if section != "" {
+		found = find(idoc, section)
+		if found == nil {
+			return nil, IncludeError(ref, "no section "+section)
+		}
+	}
+	next := append(append([]string{}, stack...), key)
+	c := found.Clone()
+	c.Prototypes = nil
+	return rd.resolve(idoc, c, ifile, next)
+}
+
+// Reads an included file, which must already exist.
+func (rd *reading) included(path string) ([]byte, error) {
+	if rd.fsys != nil {
+		return fs.ReadFile(rd.fsys, path)
+	}
+	return os.ReadFile(path)
+}
+
+func (rd *reading) relative(file, target string) string {
+	if rd.fsys != nil {
+		if path.IsAbs(target) {
+			return strings.TrimPrefix(target, "/")
+		}
+		return path.Join(path.Dir(file), target)
+	}
+	if filepath.IsAbs(target) {
+		return target
+	}
+	return filepath.Join(filepath.Dir(file), target)
+}
+
+// Returns the first Level, in order, whose tag path ends with the provided tag
+// path, or nil.
+func find(lv *Level, section string) *Level {
+	want := strings.Split(strings.Trim(section, "/"), "/")
+	var ret *Level
+	var search func(*Level, []string)
+	search = func(l *Level, tags []string) {
+		if ret != nil {
+			return
+		}
+		tags = append(tags, l.Tag)
+		if len(tags) >= len(want) {
+			match := true
+			for i, w := range want {
+				if tags[len(tags)-len(want)+i] != w {
+					match = false
+					break
+				}
+			}
+			if match {
+				ret = l
+				return
+			}
+		}
+		for _, c := range l.Levels {
+			search(c, tags)
+		}
+	}
+	search(lv, nil)
+	return ret
+}
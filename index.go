@@ -0,0 +1,112 @@
+package skelington
+
+import "strings"
+
+type index struct {
+	key    map[string]Handle
+	unit   map[string][]Handle
+	family map[string][]Handle
+}
+
+func newIndex(hs []Handle) *index {
+	i := &index{
+		make(map[string]Handle),
+		make(map[string][]Handle),
+		make(map[string][]Handle),
+	}
+	i.add(hs...)
+	return i
+}
+
+func familyPath(h Handle) string {
+	var ret []string
+	for _, t := range h.Family() {
+		if t.Value != "" {
+			ret = append(ret, t.Value)
+		}
+	}
+	return strings.Join(ret, "/")
+}
+
+func (i *index) add(hs ...Handle) {
+	for _, h := range hs {
+		i.key[h.Key()] = h
+		u := h.Unit().Value
+		i.unit[u] = append(i.unit[u], h)
+		f := familyPath(h)
+		i.family[f] = append(i.family[f], h)
+	}
+}
+
+func without(hs []Handle, rm map[string]bool) []Handle {
+	var ret []Handle
+	for _, h := range hs {
+		if !rm[h.Key()] {
+			ret = append(ret, h)
+		}
+	}
+	return ret
+}
+
+func (i *index) remove(rm map[string]bool) {
+	for k := range rm {
+		h, ok := i.key[k]
+		if !ok {
+			continue
+		}
+		delete(i.key, k)
+		u, f := h.Unit().Value, familyPath(h)
+		if i.unit[u] = without(i.unit[u], rm); len(i.unit[u]) == 0 {
+			delete(i.unit, u)
+		}
+		if i.family[f] = without(i.family[f], rm); len(i.family[f]) == 0 {
+			delete(i.family, f)
+		}
+	}
+}
+
+func (s *Skelington) index() *index {
+	if s.idx == nil {
+		s.idx = newIndex(s.Has)
+	}
+	return s.idx
+}
+
+// Rebuilds the indexes of the Skelington instance from Has. Indexes are kept
+// consistent through Add, Remove, Clear and sequencing, and only need to be
+// rebuilt after Has is modified directly.
+func (s *Skelington) Reindex() {
+	s.idx = newIndex(s.Has)
+}
+
+// Removes any of the provided handles from the Skelington instance. Remaining
+// handles are not resequenced.
+func (s *Skelington) Remove(hs ...Handle) {
+	rm := make(map[string]bool)
+	for _, h := range hs {
+		rm[h.Key()] = true
+	}
+	s.Has = without(s.Has, rm)
+	if s.Has == nil {
+		s.Has = make([]Handle, 0)
+	}
+	s.index().remove(rm)
+}
+
+// Returns the handle with the provided key, and whether it was found.
+func (s *Skelington) ByKey(k string) (Handle, bool) {
+	h, ok := s.index().key[k]
+	return h, ok
+}
+
+// Returns all handles with the provided unit tag, in the order of Has. The
+// returned array must not be modified.
+func (s *Skelington) ByUnit(tag string) []Handle {
+	return s.index().unit[tag]
+}
+
+// Returns all handles with the provided family path, e.g. 'Universe/Galaxy', in
+// the order of Has. The returned array must not be modified.
+func (s *Skelington) ByFamily(path string) []Handle {
+	return s.index().family[strings.Trim(path, "/")]
+}
@@ -0,0 +1,86 @@
+package skelington
+
+import (
+	"iter"
+
+	"github.com/Laughs-In-Flowers/xrr"
+)
+
+var ItemTypeError = xrr.Xrror("item of handle %s is %T, not %T").Out
+
+// A function taking a Handle and returning a new item for it.
+type ItemFactory func(Handle) interface{}
+
+// Returns an ItemFactory from the provided function returning a typed item.
+func TypedFactory[T any](fn func(Handle) T) ItemFactory {
+	return func(h Handle) interface{} {
+		return fn(h)
+	}
+}
+
+// Returns the item of the provided handle as T, and whether the item is a T.
+func ItemOf[T any](h Handle) (T, bool) {
+	t, ok := h.Item().(T)
+	return t, ok
+}
+
+// A function taking a Handle and its item of type T and returning an error.
+type TypedHandleCall[T any] func(Handle, T) error
+
+// Returns a HandleCall providing the provided function the item of each handle
+// as T, returning an ItemTypeError for any handle with an item that is not a T.
+func TypedCall[T any](fn TypedHandleCall[T]) HandleCall {
+	return func(h Handle) error {
+		t, ok := ItemOf[T](h)
+		if !ok {
+			var zero T
+			return ItemTypeError(h.Path(), h.Item(), zero)
+		}
+		return fn(h, t)
+	}
+}
+
+// A Skelington whose every handle carries an item of type T.
+type TypedSkelington[T any] struct {
+	*Skelington
+}
+
+// Creates a new TypedSkelington instance from provided Config, providing every
+// handle an item from the provided function.
+func NewTyped[T any](fn func(Handle) T, cnf ...Config) (*TypedSkelington[T], error) {
+	s, err := New(append(cnf, SetItemFactory(TypedFactory(fn)))...)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, nil
+	}
+	return &TypedSkelington[T]{s}, nil
+}
+
+// Returns the item of the provided handle as T, and whether the item is a T.
+func (t *TypedSkelington[T]) Item(h Handle) (T, bool) {
+	return ItemOf[T](h)
+}
+
+// Yields every handle with its item, skipping any handle whose item is not a T.
+func (t *TypedSkelington[T]) Items() iter.Seq2[Handle, T] {
+	return func(yield func(Handle, T) bool) {
+		for _, h := range t.Has {
+			if v, ok := ItemOf[T](h); ok {
+				if !yield(h, v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Sets TypedHandleCall as SkelingtonHandleCalls.
+func (t *TypedSkelington[T]) HandleCalls(hf ...TypedHandleCall[T]) {
+	var calls []HandleCall
+	for _, fn := range hf {
+		calls = append(calls, TypedCall(fn))
+	}
+	SkelingtonHandleCalls(t.Skelington, calls...)
+}
@@ -2,11 +2,14 @@ package skelington
 
 import (
 	"bytes"
+	"context"
+	"io"
+	"io/fs"
 	"os"
-	"path/filepath"
+	"path"
 	"regexp"
 
-	yaml "gopkg.in/yaml.v2"
+	yaml "gopkg.in/yaml.v3"
 )
 
 // A recursive structure used as a tool for exploring and creating handles.
@@ -14,13 +17,26 @@ type Level struct {
 	parent   *Level
 	depth    int
 	sequence *Sequence
-	Tag      string
-	Leaf     bool
-	Relative bool
-	Number   int
-	Percent  float64
-	Actual   int
-	Levels   []*Level
+	file     string
+	at       position
+	keys     map[string]position
+	Tag      string   `yaml:"tag" json:"tag" toml:"tag"`
+	Leaf     bool     `yaml:"leaf,omitempty" json:"leaf,omitempty" toml:"leaf,omitempty"`
+	Relative bool     `yaml:"relative,omitempty" json:"relative,omitempty" toml:"relative,omitempty"`
+	Number   int      `yaml:"number,omitempty" json:"number,omitempty" toml:"number,omitempty"`
+	Percent  float64  `yaml:"percent,omitempty" json:"percent,omitempty" toml:"percent,omitempty"`
+	Actual   int      `yaml:"actual,omitempty" json:"actual,omitempty" toml:"actual,omitempty"`
+	Min      int      `yaml:"min,omitempty" json:"min,omitempty" toml:"min,omitempty"`
+	Max      int      `yaml:"max,omitempty" json:"max,omitempty" toml:"max,omitempty"`
+	Exact    *int     `yaml:"exact,omitempty" json:"exact,omitempty" toml:"exact,omitempty"`
+	Rounding string   `yaml:"rounding,omitempty" json:"rounding,omitempty" toml:"rounding,omitempty"`
+	Levels   []*Level `yaml:"levels,omitempty" json:"levels,omitempty" toml:"levels,omitempty"`
+	Include  string   `yaml:"include,omitempty" json:"include,omitempty" toml:"include,omitempty"`
+	Ref      string   `yaml:"$ref,omitempty" json:"$ref,omitempty" toml:"$ref,omitempty"`
+	Use      string   `yaml:"use,omitempty" json:"use,omitempty" toml:"use,omitempty"`
+
+	Attributes map[string]interface{} `yaml:"attributes,omitempty" json:"attributes,omitempty" toml:"attributes,omitempty"`
+	Prototypes map[string]*Level      `yaml:"prototypes,omitempty" json:"prototypes,omitempty" toml:"prototypes,omitempty"`
 }
 
 func emptyLevel(tag string) *Level {
@@ -30,28 +46,28 @@ func emptyLevel(tag string) *Level {
 	}
 }
 
-// Provided a path string, will attempt to read a yaml file there, creating a new
-// Level instance and an error.
-func ReadFromFile(path string) (*Level, error) {
-	var f *os.File
-	var err error
-	f, err = open(path)
+// Provided a path string, will attempt to read a yaml, json or toml file there,
+// creating a new Level instance and an error. The format is detected by file
+// extension, then by content, unless provided with ReadFormat.
+func ReadFromFile(path string, opts ...ReadOption) (*Level, error) {
+	rd := newReading(opts...)
+	raw, err := rd.read(path)
 	if err != nil {
 		return nil, err
 	}
+	return rd.level(path, raw)
+}
+
+// Provided an io.Reader, will attempt to read a yaml, json or toml specification
+// from it, creating a new Level instance and an error. The format is detected by
+// content, unless provided with ReadFormat.
+func ReadFrom(r io.Reader, opts ...ReadOption) (*Level, error) {
 	b := bytes.NewBuffer(make([]byte, 0, bytes.MinRead))
-	_, err = b.ReadFrom(f)
+	_, err := b.ReadFrom(r)
 	if err != nil {
 		return nil, err
 	}
-	raw := b.Bytes()
-	lv := &Level{}
-	err = yaml.Unmarshal(raw, lv)
-	if err != nil {
-		return nil, err
-	}
-	notate(lv, lv.Levels, 0)
-	return lv, nil
+	return newReading(opts...).level("", b.Bytes())
 }
 
 func notate(p *Level, ls []*Level, d int) {
@@ -64,16 +80,36 @@ func notate(p *Level, ls []*Level, d int) {
 }
 
 // Provided a path string, will attempt to read from that directory to create a
-// new Level instance and an error.
-func ReadFromDirectory(path string, offset string) (*Level, error) {
+// new Level instance and an error. Directories matching the offset are counted
+// as sequences, or if no offset is provided those matching the pattern of any
+// ReadSequenceFormat. If provided with ReadFS, the path is read from that file
+// system instead of the operating system.
+func ReadFromDirectory(path string, offset string, opts ...ReadOption) (*Level, error) {
+	rd := newReading(opts...)
+	if rd.fsys != nil {
+		return rd.readFS(rd.fsys, path, path, offset)
+	}
+	return rd.readFS(os.DirFS(path), ".", path, offset)
+}
+
+// Provided a file system and a root path within it, will attempt to read from
+// that directory to create a new Level instance and an error.
+func ReadFromFS(fsys fs.FS, root string, offset string, opts ...ReadOption) (*Level, error) {
+	return newReading(opts...).readFS(fsys, root, root, offset)
+}
+
+func (rd *reading) readFS(fsys fs.FS, root, tag, offset string) (*Level, error) {
+	if offset == "" && rd.sequence != nil {
+		offset = rd.sequence.Pattern()
+	}
 	var seq *regexp.Regexp
 	var err error
 	seq, err = regexp.Compile(offset)
 	if err != nil {
 		return nil, err
 	}
-	lv := emptyLevel(path)
-	lv, err = walk(lv, path, seq)
+	lv := emptyLevel(tag)
+	lv, err = walk(rd.ctx, lv, fsys, root, seq)
 	if err != nil {
 		return nil, err
 	}
@@ -81,44 +117,67 @@ func ReadFromDirectory(path string, offset string) (*Level, error) {
 	return lv, nil
 }
 
-func walk(lv *Level, path string, seq *regexp.Regexp) (*Level, error) {
+func walk(ctx context.Context, lv *Level, fsys fs.FS, root string, seq *regexp.Regexp) (*Level, error) {
 	flat := make(map[string]*Level)
-	flat[filepath.Base(path)] = lv
+	flat[root] = lv
 
-	resErr := filepath.Walk(path, func(p string, f os.FileInfo, e error) error {
-		var base string = filepath.Base(p)
-		var err error
-		var fl *os.File
-		fl, err = os.Open(p)
-		if err != nil {
+	resErr := fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, e error) error {
+		if e != nil {
+			return e
+		}
+		if err := ctx.Err(); err != nil {
 			return err
 		}
-		var dirs []string
-		dirs, err = fl.Readdirnames(-1)
+		if !d.IsDir() {
+			return nil
+		}
+		par, ok := flat[p]
+		if !ok {
+			return fs.SkipDir
+		}
+		entries, err := fs.ReadDir(fsys, p)
 		if err != nil {
 			return err
 		}
-		for _, dir := range dirs {
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				if entry.Name() == AttributesFile {
+					err = attribute(par, fsys, path.Join(p, entry.Name()))
+					if err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			dir := entry.Name()
 			switch {
 			case seq.MatchString(dir):
-				if par, ok := flat[base]; ok {
-					par.Number = par.Number + 1
-				}
+				par.Number = par.Number + 1
 			default:
 				clv := emptyLevel(dir)
-				if par, ok := flat[base]; ok {
-					clv.parent = par
-					par.Levels = append(par.Levels, clv)
-				}
-				flat[dir] = clv
+				clv.parent = par
+				par.Levels = append(par.Levels, clv)
+				flat[path.Join(p, dir)] = clv
 			}
 		}
-		return err
+		return nil
 	})
 
 	return lv, resErr
 }
 
+// The name of a file within a directory read by ReadFromDirectory providing yaml
+// attributes for the Level of that directory.
+var AttributesFile string = ".attributes.yaml"
+
+func attribute(lv *Level, fsys fs.FS, p string) error {
+	b, err := fs.ReadFile(fsys, p)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(b, &lv.Attributes)
+}
+
 func reverse(in []string) []string {
 	for i, j := 0, len(in)-1; i < j; i, j = i+1, j-1 {
 		in[i], in[j] = in[j], in[i]
@@ -152,6 +211,26 @@ func (lv *Level) Family() []*Tag {
 	return ret
 }
 
+// Returns the attributes of the Level, inherited from every parent Level with
+// those of the nearest Level taking precedence.
+func (lv *Level) attributes() map[string]interface{} {
+	var ret map[string]interface{}
+	if lv.parent != nil {
+		ret = lv.parent.attributes()
+	}
+	if len(lv.Attributes) == 0 {
+		return ret
+	}
+	merged := make(map[string]interface{}, len(ret)+len(lv.Attributes))
+	for k, v := range ret {
+		merged[k] = v
+	}
+	for k, v := range lv.Attributes {
+		merged[k] = v
+	}
+	return merged
+}
+
 // Return the Level unit Tag.
 func (lv *Level) Unit() *Tag {
 	t := &Tag{}
@@ -198,15 +277,6 @@ func (lv *Level) Iter(fn func(*Level)) {
 	}
 }
 
-func branch(lv *Level) {
-	for _, v := range lv.Levels {
-		if isLeaf(v) {
-			add := v.CloneMultiple(v.Number - 1)
-			lv.Levels = append(lv.Levels, add...)
-		}
-	}
-}
-
 func flatten(lv *Level) []*Level {
 	f := &flat{}
 	lv.Iter(f.flatten)
@@ -0,0 +1,146 @@
+package skelington
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/Laughs-In-Flowers/xrr"
+)
+
+// A type for specifying how materialization treats paths that already exist.
+type ConflictPolicy int
+
+const (
+	ConflictSkip      ConflictPolicy = iota // leave existing paths untouched
+	ConflictFail                            // fail before creating anything
+	ConflictOverwrite                       // remove and recreate existing paths
+)
+
+// A function configuring a materialization.
+type MaterializeOption func(*materializer)
+
+type materializer struct {
+	dryRun   bool
+	out      io.Writer
+	conflict ConflictPolicy
+	mode     os.FileMode
+}
+
+func newMaterializer(opts ...MaterializeOption) *materializer {
+	m := &materializer{
+		out:  os.Stdout,
+		mode: DefaultDirMode,
+	}
+	for _, fn := range opts {
+		fn(m)
+	}
+	return m
+}
+
+// Writes what would be done to the provided io.Writer instead of touching the
+// file system.
+func MaterializeDryRun(w io.Writer) MaterializeOption {
+	return func(m *materializer) {
+		m.dryRun = true
+		if w != nil {
+			m.out = w
+		}
+	}
+}
+
+// Sets the ConflictPolicy used for paths that already exist, the default being
+// ConflictSkip.
+func MaterializeConflict(c ConflictPolicy) MaterializeOption {
+	return func(m *materializer) {
+		m.conflict = c
+	}
+}
+
+// Sets the permission bits used for created directories, the default being
+// DefaultDirMode.
+func MaterializeMode(mode os.FileMode) MaterializeOption {
+	return func(m *materializer) {
+		m.mode = mode.Perm()
+	}
+}
+
+// A record of what a materialization created, skipped or overwrote.
+type MaterializeReport struct {
+	Destination string
+	DryRun      bool
+	Created     []string
+	Skipped     []string
+	Overwritten []string
+}
+
+var MaterializeError = xrr.Xrror("unable to materialize %s: %s").Out
+
+// Creates a directory for every Handle Path of the provided Skelington under the
+// destination, returning a report of what was done and any error.
+func Materialize(s *Skelington, dest string, opts ...MaterializeOption) (*MaterializeReport, error) {
+	m := newMaterializer(opts...)
+	r := &MaterializeReport{
+		Destination: dest,
+		DryRun:      m.dryRun,
+	}
+
+	paths := make([]string, 0, len(s.Has))
+	existing := make(map[string]bool)
+	for _, h := range s.Has {
+		p := filepath.Join(dest, h.Path())
+		if _, err := os.Stat(p); err == nil {
+			if m.conflict == ConflictFail {
+				return r, MaterializeError(p, "path exists")
+			}
+			existing[p] = true
+		}
+		paths = append(paths, p)
+	}
+
+	for _, p := range paths {
+		var err error
+		switch {
+		case existing[p] && m.conflict == ConflictSkip:
+			r.Skipped = append(r.Skipped, p)
+			err = m.report("skip", p)
+		case existing[p]:
+			r.Overwritten = append(r.Overwritten, p)
+			err = m.overwrite(p)
+		default:
+			r.Created = append(r.Created, p)
+			err = m.create(p)
+		}
+		if err != nil {
+			return r, MaterializeError(p, err)
+		}
+	}
+
+	return r, nil
+}
+
+func (m *materializer) report(action, path string) error {
+	if m.dryRun {
+		_, err := fmt.Fprintf(m.out, "%s %s\n", action, path)
+		return err
+	}
+	return nil
+}
+
+func (m *materializer) create(path string) error {
+	if m.dryRun {
+		return m.report("create", path)
+	}
+	return os.MkdirAll(path, os.ModeDir|m.mode)
+}
+
+func (m *materializer) overwrite(path string) error {
+	if m.dryRun {
+		return m.report("overwrite", path)
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return err
+	}
+	return os.MkdirAll(path, os.ModeDir|m.mode)
+}
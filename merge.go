@@ -0,0 +1,96 @@
+package skelington
+
+import "github.com/Laughs-In-Flowers/xrr"
+
+// A type for specifying how handles sharing a path are merged.
+type DuplicatePolicy int
+
+const (
+	DuplicateKeepFirst DuplicatePolicy = iota // keep only the first handle of a path
+	DuplicateKeepBoth                         // keep every handle of a path
+	DuplicateFail                             // stop merging with a MergeError
+)
+
+var MergeError = xrr.Xrror("unable to merge: duplicate path %s").Out
+
+// Merges the provided Skelington instances into a new Skelington instance,
+// keeping only the first handle of any duplicate path. See MergeWith.
+func Merge(ss ...*Skelington) (*Skelington, error) {
+	return MergeWith(DuplicateKeepFirst, ss...)
+}
+
+// Merges the provided Skelington instances into a new Skelington instance, by
+// the provided DuplicatePolicy for handles sharing a path. Handles are cloned,
+// without any HandleCall, and sequenced again across the merged instance. Hooks
+// and statistics functions configured for each instance are merged in order.
+// The merged instance cannot be reallocated.
+func MergeWith(d DuplicatePolicy, ss ...*Skelington) (*Skelington, error) {
+	p := &Processor{
+		hookHolder: make(map[HookTiming][]SkelingtonHook),
+		statHolder: make(map[string][]StatFunc),
+	}
+	seen := make(map[*Processor]bool)
+	for _, s := range ss {
+		if s == nil || s.p == nil || seen[s.p] {
+			continue
+		}
+		seen[s.p] = true
+		for k, v := range s.p.hookHolder {
+			p.hookHolder[k] = append(p.hookHolder[k], v...)
+		}
+		for k, v := range s.p.statHolder {
+			p.statHolder[k] = append(p.statHolder[k], v...)
+		}
+		if p.itemFactory == nil {
+			p.itemFactory = s.p.itemFactory
+		}
+		if p.sequenceFormat == nil {
+			p.sequenceFormat = s.p.sequenceFormat
+		}
+	}
+
+	paths := make(map[string]bool)
+	var add []Handle
+	for _, s := range ss {
+		if s == nil {
+			continue
+		}
+		for _, h := range s.Has {
+			path := h.Path()
+			if paths[path] {
+				switch d {
+				case DuplicateKeepFirst:
+					continue
+				case DuplicateFail:
+					return nil, MergeError(path)
+				}
+			}
+			paths[path] = true
+			add = append(add, clone(h))
+		}
+	}
+
+	m := newSkelington(p)
+	m.AddHook(HPost, SkelingtonSequence)
+	if err := m.RunHook(HBefore); err != nil {
+		return nil, err
+	}
+	if err := m.Add(add...); err != nil {
+		return nil, err
+	}
+	if err := m.RunHook(HAfter); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func clone(h Handle) Handle {
+	var seq *Sequence
+	if s := h.Sequence(); s != nil {
+		ns := *s
+		seq = &ns
+	}
+	nh := newHandle(seq, h.Root(), h.Family(), h.Unit(), attributesOf(h))
+	nh.SetItem(h.Item())
+	return nh
+}
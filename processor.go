@@ -1,18 +1,26 @@
 package skelington
 
 import (
+	"context"
 	"fmt"
+	"iter"
 	"os"
 )
 
 // A core processing type gathering everything required for Skelington generation.
 type Processor struct {
-	root         Pather
-	file         Pather
-	errorHandler ErrorHandling
-	offset       string
-	hookHolder   map[HookTiming][]SkelingtonHook
-	statHolder   map[string][]StatFunc
+	root           Pather
+	file           Pather
+	errorHandler   ErrorHandling
+	offset         string
+	seed           int64
+	seeded         bool
+	rounding       Rounding
+	hookHolder     map[HookTiming][]SkelingtonHook
+	statHolder     map[string][]StatFunc
+	readOptions    []ReadOption
+	itemFactory    ItemFactory
+	sequenceFormat SequenceFormat
 	Allocator
 }
 
@@ -30,11 +38,96 @@ func newProcessor(cnf ...Config) (*Processor, error) {
 	return p, nil
 }
 
-// The core function that produces a Skelington instance from an allocation strategy.
-func (p *Processor) Process() *Skelington {
-	s := newSkelington(p.hookHolder, p.statHolder)
-	ret := p.Allocate(s, p.file, p.root, p.offset, p.manageError)
-	return ret
+// The core function that produces a Skelington instance from an allocation
+// strategy, returning any error stopping the allocation.
+func (p *Processor) Process() (*Skelington, error) {
+	s := newSkelington(p)
+	return p.allocate(s)
+}
+
+// Produces a Skelington instance as Process, stopping allocation and returning
+// the context error if the provided context is done. The context governs only
+// the allocation, and not the Skelington instance once returned.
+func (p *Processor) ProcessContext(ctx context.Context) (*Skelington, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s := newSkelington(p)
+	s.ctx = ctx
+	ret, err := p.allocate(s)
+	s.ctx = nil
+	if cerr := ctx.Err(); cerr != nil {
+		return nil, cerr
+	}
+	return ret, err
+}
+
+// Produces a Skelington instance and an iterator yielding handles one at a time
+// from the allocation strategy. Hooks and statistics are run on the Skelington
+// instance as each handle is yielded, but handles are not retained by it. Any
+// error stopping the allocation is yielded last, with a nil Handle.
+func (p *Processor) Stream() (*Skelington, iter.Seq2[Handle, error]) {
+	return p.StreamContext(context.Background())
+}
+
+// Produces a Skelington instance and iterator as Stream, no longer yielding
+// handles once the provided context is done, but yielding the context error.
+// The context governs only the iteration, and not the Skelington instance
+// otherwise. An Allocator that is not a StreamAllocator allocates in full before
+// any handle is yielded.
+func (p *Processor) StreamContext(ctx context.Context) (*Skelington, iter.Seq2[Handle, error]) {
+	s := newSkelington(p)
+	seq := func(yield func(Handle, error) bool) {
+		ret, err := p.allocate(s)
+		if ret == nil {
+			if err != nil {
+				yield(nil, err)
+			}
+			return
+		}
+		hs := s.Has
+		s.Clear()
+		for _, h := range hs {
+			if !yield(h, nil) {
+				return
+			}
+		}
+	}
+	if sa, ok := p.Allocator.(StreamAllocator); ok {
+		seq = sa.Stream(s, p.file, p.root, p.offset, p.manageError)
+	}
+	return s, func(yield func(Handle, error) bool) {
+		prev := s.ctx
+		s.ctx = ctx
+		defer func() {
+			s.ctx = prev
+		}()
+		stopped := false
+		seq(func(h Handle, err error) bool {
+			stopped = !yield(h, err)
+			return !stopped
+		})
+		if err := ctx.Err(); err != nil && !stopped {
+			yield(nil, err)
+		}
+	}
+}
+
+// Allocates to the Skelington instance, returning the first error handled when
+// the allocation stops without a result, as it does for an error that is not
+// fatal to the error handling.
+func (p *Processor) allocate(s *Skelington) (*Skelington, error) {
+	var err error
+	ret := p.Allocate(s, p.file, p.root, p.offset, func(e error) {
+		if err == nil {
+			err = e
+		}
+		p.manageError(e)
+	})
+	if ret != nil {
+		return ret, nil
+	}
+	return nil, err
 }
 
 func (p *Processor) manageError(e error) {
@@ -0,0 +1,117 @@
+package skelington
+
+import (
+	"strings"
+
+	"github.com/Laughs-In-Flowers/xrr"
+)
+
+var PrototypeError = xrr.Xrror("unable to use prototype %s: %s").Out
+
+// Instantiates the prototype named by the 'use' of the Level, from the
+// 'prototypes' of the specification it was read from, e.g.
+//
+//	prototypes:
+//	  Planet:
+//	    number: 2
+//	    levels:
+//	      - tag: Moon
+//	        number: 1
+//	levels:
+//	  - use: Planet
+//	    number: 3
+func (rd *reading) prototype(doc, lv *Level, file string, stack []string) (*Level, error) {
+	key := file + "@" + lv.Use
+	for _, k := range stack {
+		if k == key {
+			return nil, PrototypeError(lv.Use, "cycle through "+strings.Join(append(stack, key), " -> "))
+		}
+	}
+	proto, ok := doc.Prototypes[lv.Use]
+	if !ok || proto == nil {
+		return nil, PrototypeError(lv.Use, "no prototype named "+lv.Use)
+	}
+
+	next := append(append([]string{}, stack...), key)
+	base, err := rd.resolve(doc, proto.Clone(), file, next)
+	if err != nil {
+		return nil, err
+	}
+	if base.Tag == "" {
+		base.Tag = lv.Use
+	}
+	return rd.overlaid(doc, base, lv, file, stack)
+}
+
+// Overlays the Level onto base, resolving any child levels of the Level that
+// replace those of base.
+func (rd *reading) overlaid(doc, base, lv *Level, file string, stack []string) (*Level, error) {
+	overlay(base, lv)
+	if len(lv.Levels) > 0 {
+		for i, c := range base.Levels {
+			nc, err := rd.resolve(doc, c, file, stack)
+			if err != nil {
+				return nil, err
+			}
+			base.Levels[i] = nc
+		}
+	}
+	return base, nil
+}
+
+// Overrides a prototype instance or included Level with any non zero value of
+// the Level using or including it. Any child levels of the Level replace those
+// of base entirely, while attributes are merged. Positions are merged only for
+// a base read from the same file.
+func overlay(base, lv *Level) {
+	if lv.Tag != "" {
+		base.Tag = lv.Tag
+	}
+	if lv.Leaf {
+		base.Leaf = true
+	}
+	if lv.Relative {
+		base.Relative = true
+	}
+	if lv.Number != 0 {
+		base.Number = lv.Number
+	}
+	if lv.Min != 0 {
+		base.Min = lv.Min
+	}
+	if lv.Max != 0 {
+		base.Max = lv.Max
+	}
+	if lv.Exact != nil {
+		base.Exact = lv.Exact
+	}
+	if lv.Rounding != "" {
+		base.Rounding = lv.Rounding
+	}
+	if len(lv.Levels) > 0 {
+		base.Levels = lv.Levels
+	}
+	if len(lv.Attributes) > 0 {
+		attributes := make(map[string]interface{})
+		for k, v := range base.Attributes {
+			attributes[k] = v
+		}
+		for k, v := range lv.Attributes {
+			attributes[k] = v
+		}
+		base.Attributes = attributes
+	}
+	base.Use = ""
+	if base.file != lv.file {
+		return
+	}
+	base.at = lv.at
+	keys := make(map[string]position)
+	for k, v := range base.keys {
+		keys[k] = v
+	}
+	for k, v := range lv.keys {
+		keys[k] = v
+	}
+	base.keys = keys
+}
@@ -0,0 +1,147 @@
+package skelington
+
+import (
+	"context"
+	"path"
+	"strings"
+)
+
+// An interface for any set of handles that hooks may be set for, i.e. a
+// Skelington instance or a View of one.
+type HandleSet interface {
+	Hooks
+	Handles() []Handle
+	Context() context.Context
+}
+
+// Returns all handles of the Skelington instance.
+func (s *Skelington) Handles() []Handle {
+	return s.Has
+}
+
+// Returns a View of all handles of the Skelington instance.
+func (s *Skelington) Query() *View {
+	return newView(s, s.Has)
+}
+
+// A lightweight selection of handles from a Skelington instance, itself able
+// to be queried further. Any hooks set on a View are set on the Skelington
+// instance it was selected from.
+type View struct {
+	s   *Skelington
+	has []Handle
+}
+
+func newView(s *Skelington, hs []Handle) *View {
+	return &View{s, hs}
+}
+
+// Adds the provided SkelingtonHook for the provided HookTiming to the Skelington
+// instance the View was selected from.
+func (v *View) AddHook(t HookTiming, sh ...SkelingtonHook) {
+	v.s.AddHook(t, sh...)
+}
+
+// Run all hooks matching the provided HookTiming on the Skelington instance the
+// View was selected from.
+func (v *View) RunHook(t HookTiming) error {
+	return v.s.RunHook(t)
+}
+
+// Returns all handles of the View.
+func (v *View) Handles() []Handle {
+	return v.has
+}
+
+// Returns the context of the Skelington instance the View was selected from.
+func (v *View) Context() context.Context {
+	return v.s.Context()
+}
+
+// Returns the number of handles in the View.
+func (v *View) Count() int {
+	return len(v.has)
+}
+
+// Returns a View of handles matching the provided predicate.
+func (v *View) Where(fn func(Handle) bool) *View {
+	var ret []Handle
+	for _, h := range v.has {
+		if fn(h) {
+			ret = append(ret, h)
+		}
+	}
+	return newView(v.s, ret)
+}
+
+// Returns a View of handles with any of the provided unit tags.
+func (v *View) Unit(tags ...string) *View {
+	return v.Where(func(h Handle) bool {
+		u := h.Unit().Value
+		for _, t := range tags {
+			if u == t {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Returns a View of handles with any of the provided root tags.
+func (v *View) Root(tags ...string) *View {
+	return v.Where(func(h Handle) bool {
+		r := h.Root().Value
+		for _, t := range tags {
+			if r == t {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Returns a View of handles whose family path matches the provided pattern,
+// e.g. 'Universe/*/Star/**'. Each element of the pattern matches one family
+// tag as path.Match, except '**' which matches any number of family tags.
+func (v *View) Family(pattern string) *View {
+	want := strings.Split(strings.Trim(pattern, "/"), "/")
+	return v.Where(func(h Handle) bool {
+		var family []string
+		for _, t := range h.Family() {
+			if t.Value != "" {
+				family = append(family, t.Value)
+			}
+		}
+		return globMatch(want, family)
+	})
+}
+
+func globMatch(pattern, tags []string) bool {
+	if len(pattern) == 0 {
+		return len(tags) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(tags); i++ {
+			if globMatch(pattern[1:], tags[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(tags) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], tags[0]); !ok {
+		return false
+	}
+	return globMatch(pattern[1:], tags[1:])
+}
+
+// Returns a View of handles whose sequence number is within the provided
+// inclusive range.
+func (v *View) Sequence(from, to int) *View {
+	return v.Where(func(h Handle) bool {
+		s := h.Sequence()
+		return s != nil && s.Number >= from && s.Number <= to
+	})
+}
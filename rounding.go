@@ -0,0 +1,119 @@
+package skelington
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/Laughs-In-Flowers/xrr"
+)
+
+// A type for specifying how proportional counts are rounded to whole handles.
+type Rounding int
+
+const (
+	FloorRounding    Rounding = iota // truncate every count
+	NearestRounding                  // round every count to the nearest whole
+	HamiltonRounding                 // largest remainder, preserving the rounded sum
+	PreserveRounding                 // largest remainder, always summing to the parent count
+)
+
+// The string value of the Rounding.
+func (r Rounding) String() string {
+	switch r {
+	case NearestRounding:
+		return "nearest"
+	case HamiltonRounding:
+		return "hamilton"
+	case PreserveRounding:
+		return "preserve"
+	}
+	return "floor"
+}
+
+var RoundingError = xrr.Xrror("unknown rounding %s").Out
+
+// Provided a string key, one of 'floor', 'nearest', 'hamilton',
+// 'largest-remainder' or 'preserve', returns the corresponding Rounding and any
+// error.
+func ParseRounding(k string) (Rounding, error) {
+	switch strings.ToLower(k) {
+	case "", "floor":
+		return FloorRounding, nil
+	case "nearest":
+		return NearestRounding, nil
+	case "hamilton", "largest-remainder":
+		return HamiltonRounding, nil
+	case "preserve":
+		return PreserveRounding, nil
+	}
+	return FloorRounding, RoundingError(k)
+}
+
+const remainderEpsilon = 1e-9
+
+// Apportions the provided count by the provided proportions.
+func (r Rounding) apportion(from int, p []float64) []int {
+	ret := make([]int, len(p))
+	switch r {
+	case NearestRounding:
+		for i, v := range p {
+			ret[i] = int(math.Floor(float64(from)*v + 0.5 + remainderEpsilon))
+		}
+	case HamiltonRounding:
+		ret = largestRemainder(from, p)
+	case PreserveRounding:
+		var total float64
+		for _, v := range p {
+			if v > 0 {
+				total = total + v
+			}
+		}
+		if total > 0 {
+			np := make([]float64, len(p))
+			for i, v := range p {
+				np[i] = v / total
+			}
+			p = np
+		}
+		ret = largestRemainder(from, p)
+	default:
+		for i, v := range p {
+			ret[i] = int(float64(from) * v)
+		}
+	}
+	return ret
+}
+
+// Floors every quota, then hands out what remains of the rounded total quota
+// one at a time by largest remainder, ties going to the earlier quota.
+func largestRemainder(from int, p []float64) []int {
+	ret := make([]int, len(p))
+	rem := make([]float64, len(p))
+	var total float64
+	var sum int
+	for i, v := range p {
+		if v < 0 {
+			v = 0
+		}
+		q := float64(from) * v
+		total = total + q
+		ret[i] = int(math.Floor(q + remainderEpsilon))
+		rem[i] = q - float64(ret[i])
+		sum = sum + ret[i]
+	}
+
+	idx := make([]int, len(p))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		return rem[idx[a]]-rem[idx[b]] > remainderEpsilon
+	})
+
+	left := int(math.Round(total)) - sum
+	for i := 0; i < left && i < len(idx); i++ {
+		ret[idx[i]] = ret[idx[i]] + 1
+	}
+	return ret
+}
@@ -0,0 +1,157 @@
+package skelington
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Laughs-In-Flowers/xrr"
+)
+
+// An interface for formatting a Sequence as a string, and parsing it back.
+// Pattern returns a regular expression matching any formatted Sequence, as used
+// to recognise sequences when reading a directory, and String a key for the
+// format, as used by ParseSequenceFormat.
+type SequenceFormat interface {
+	Format(*Sequence) string
+	Parse(string) (*Sequence, error)
+	Pattern() string
+	String() string
+}
+
+var SequenceParseError = xrr.Xrror("unable to parse sequence %s as %s").Out
+
+type sequenceFormat struct {
+	name    string
+	pattern *regexp.Regexp
+	format  func(*Sequence) string
+}
+
+func (f *sequenceFormat) Format(s *Sequence) string {
+	return f.format(s)
+}
+
+func (f *sequenceFormat) Parse(in string) (*Sequence, error) {
+	m := f.pattern.FindStringSubmatch(in)
+	if m == nil {
+		return nil, SequenceParseError(in, f.name)
+	}
+	ret := &Sequence{}
+	var err error
+	if ret.Number, err = strconv.Atoi(m[1]); err != nil {
+		return nil, SequenceParseError(in, f.name)
+	}
+	if len(m) > 2 {
+		if ret.Count, err = strconv.Atoi(m[2]); err != nil {
+			return nil, SequenceParseError(in, f.name)
+		}
+	}
+	return ret, nil
+}
+
+func (f *sequenceFormat) Pattern() string {
+	return f.pattern.String()
+}
+
+func (f *sequenceFormat) String() string {
+	return f.name
+}
+
+type defaultSequenceFormat struct{}
+
+func (defaultSequenceFormat) Format(s *Sequence) string {
+	return s.String()
+}
+
+func (defaultSequenceFormat) Parse(in string) (*Sequence, error) {
+	return (&sequenceFormat{
+		"default", regexp.MustCompile(DefaultSequencePatternString), nil,
+	}).Parse(in)
+}
+
+func (defaultSequenceFormat) Pattern() string {
+	return DefaultSequencePatternString
+}
+
+func (defaultSequenceFormat) String() string {
+	return "default"
+}
+
+var (
+	// Formats as DefaultSequenceNumericalFmt and parses as DefaultSequencePatternString,
+	// e.g. '7-of-100'.
+	DefaultSequence SequenceFormat = defaultSequenceFormat{}
+
+	// Formats the number zero padded to the width of the count, e.g. '007-of-100'.
+	PaddedSequence SequenceFormat = &sequenceFormat{
+		"padded",
+		regexp.MustCompile(`^([0-9]+)-of-([0-9]+)$`),
+		func(s *Sequence) string {
+			w := len(strconv.Itoa(s.Count))
+			return fmt.Sprintf("%0*d-of-%d", w, s.Number, s.Count)
+		},
+	}
+
+	// Formats the number and count separated by an underscore, e.g. '7_100'.
+	UnderscoreSequence SequenceFormat = &sequenceFormat{
+		"underscore",
+		regexp.MustCompile(`^([0-9]+)_([0-9]+)$`),
+		func(s *Sequence) string {
+			return fmt.Sprintf("%d_%d", s.Number, s.Count)
+		},
+	}
+
+	// Formats the number alone, e.g. '7', parsing to a Sequence without a count.
+	NumberSequence SequenceFormat = &sequenceFormat{
+		"number",
+		regexp.MustCompile(`^([0-9]+)$`),
+		func(s *Sequence) string {
+			return strconv.Itoa(s.Number)
+		},
+	}
+)
+
+var SequenceFormatError = xrr.Xrror("unknown sequence format %s").Out
+
+// Provided a string key, one of 'default', 'padded', 'underscore' or 'number',
+// returns the corresponding SequenceFormat and any error.
+func ParseSequenceFormat(k string) (SequenceFormat, error) {
+	switch strings.ToLower(k) {
+	case "", "default":
+		return DefaultSequence, nil
+	case "padded":
+		return PaddedSequence, nil
+	case "underscore":
+		return UnderscoreSequence, nil
+	case "number":
+		return NumberSequence, nil
+	}
+	return nil, SequenceFormatError(k)
+}
+
+// Recognises sequences when reading a directory by the provided SequenceFormat,
+// when no offset is provided.
+func ReadSequenceFormat(f SequenceFormat) ReadOption {
+	return func(rd *reading) {
+		rd.sequence = f
+	}
+}
+
+func (s *Skelington) sequenceFormat() SequenceFormat {
+	if s.p != nil {
+		return s.p.sequenceFormat
+	}
+	return nil
+}
+
+func formatSequence(h Handle) string {
+	seq := h.Sequence()
+	if seq == nil {
+		return ""
+	}
+	if fh, ok := h.(*handle); ok && fh.format != nil {
+		return fh.format.Format(seq)
+	}
+	return seq.String()
+}
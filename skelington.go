@@ -1,58 +1,205 @@
 package skelington
 
+import (
+	"context"
+	"iter"
+
+	"github.com/Laughs-In-Flowers/xrr"
+)
+
 // A struct generated to specification containing a flattened array of Handle
 // and hook functionality.
 type Skelington struct {
 	Has []Handle
 	Hooks
 	Statistic
+	p   *Processor
+	ctx context.Context
+	idx *index
 }
 
-// Creates new Skelington instance from provided Config
+// Creates new Skelington instance from provided Config, returning any error
+// configuring or stopping allocation.
 func New(cnf ...Config) (*Skelington, error) {
 	p, pErr := newProcessor(cnf...)
 	if pErr != nil {
 		return nil, pErr
 	}
-	s := p.Process()
-	return s, nil
+	return p.Process()
+}
+
+// Creates new Skelington instance from provided Config, stopping allocation and
+// returning the context error if the provided context is done.
+func NewContext(ctx context.Context, cnf ...Config) (*Skelington, error) {
+	p, pErr := newProcessor(cnf...)
+	if pErr != nil {
+		return nil, pErr
+	}
+	return p.ProcessContext(ctx)
+}
+
+// Creates a new Skelington instance and an iterator streaming handles from the
+// provided Config, as Processor Stream.
+func NewStream(cnf ...Config) (*Skelington, iter.Seq2[Handle, error], error) {
+	p, pErr := newProcessor(cnf...)
+	if pErr != nil {
+		return nil, nil, pErr
+	}
+	s, seq := p.Stream()
+	return s, seq, nil
 }
 
-func newSkelington(
-	h map[HookTiming][]SkelingtonHook,
-	m map[string][]StatFunc,
-) *Skelington {
+func newSkelington(p *Processor) *Skelington {
 	s := &Skelington{
-		make([]Handle, 0), nil, nil,
+		make([]Handle, 0), nil, nil, p, nil, nil,
+	}
+	s.configure()
+	return s
+}
+
+func (s *Skelington) configure() {
+	var h map[HookTiming][]SkelingtonHook
+	var m map[string][]StatFunc
+	if s.p != nil {
+		h = s.p.hookHolder
+		m = s.p.statHolder
 	}
 	s.Hooks = newHooks(s)
 	for k, v := range h {
 		s.AddHook(k, v...)
 	}
 	s.Statistic = newStat(s, m)
-	return s
 }
 
-// Adds any number of Handle instance to Skelington instance.
+// Adds any number of Handle instance to Skelington instance, providing any handle
+// without an item one from any configured ItemFactory, and any configured
+// SequenceFormat.
 func (s *Skelington) Add(nhs ...Handle) error {
 	preErr := s.RunHook(HPre)
 	if preErr != nil {
 		return preErr
 	}
+	if s.p != nil && s.p.itemFactory != nil {
+		for _, h := range nhs {
+			if h.Item() == nil {
+				h.SetItem(s.p.itemFactory(h))
+			}
+		}
+	}
+	if f := s.sequenceFormat(); f != nil {
+		for _, h := range nhs {
+			if fh, ok := h.(*handle); ok && fh.format == nil {
+				fh.format = f
+			}
+		}
+	}
+	idx := s.index()
 	s.Has = append(s.Has, nhs...)
+	idx.add(nhs...)
 	postErr := s.RunHook(HPost)
 	return postErr
 }
 
-//func (s *Skelington) Clear() {
-//reset all hooks to defaults
-//empty handles
-//reset stats
-//}
+// Returns the context of the Skelington instance, as provided to any allocation
+// in progress by NewContext, ProcessContext, StreamContext or ReallocateContext,
+// or a background context.
+func (s *Skelington) Context() context.Context {
+	if s.ctx != nil {
+		return s.ctx
+	}
+	return context.Background()
+}
+
+func (s *Skelington) cancelled() bool {
+	return s.Context().Err() != nil
+}
+
+// Returns the seed used by any stochastic allocator of the Skelington instance,
+// as provided with SetSeed or drawn from the current time.
+func (s *Skelington) Seed() int64 {
+	if s.p != nil {
+		return s.p.seed
+	}
+	return 0
+}
+
+func (s *Skelington) rounding() Rounding {
+	if s.p != nil {
+		return s.p.rounding
+	}
+	return FloorRounding
+}
+
+func (s *Skelington) readOptions() []ReadOption {
+	var ret []ReadOption
+	if s.p != nil {
+		ret = append(ret, s.p.readOptions...)
+	}
+	return append(ret, ReadContext(s.Context()))
+}
+
+// Empties the Skelington instance of all Handle.
+func (s *Skelington) Clear() {
+	s.Has = make([]Handle, 0)
+	s.Reindex()
+}
+
+// Restores hooks to those configured by the Processor that produced this
+// Skelington instance, and zeroes all statistics.
+func (s *Skelington) Reset() {
+	s.configure()
+	s.Statistic.Reset()
+}
+
+var ReallocateError = xrr.Xrror("unable to reallocate: %s").Out
+
+// Clears and resets the Skelington instance, then runs the original Processor
+// allocation against the same root, file and offset.
+func (s *Skelington) Reallocate() error {
+	return s.ReallocateContext(s.Context())
+}
+
+// Reallocates the Skelington instance as Reallocate, stopping reallocation and
+// returning the context error if the provided context is done. The context
+// governs only the reallocation.
+func (s *Skelington) ReallocateContext(ctx context.Context) error {
+	if s.p == nil || s.p.Allocator == nil {
+		return ReallocateError("no processor available")
+	}
+	prev := s.ctx
+	s.ctx = ctx
+	defer func() {
+		s.ctx = prev
+	}()
+	s.Clear()
+	s.Reset()
+	r, err := s.p.allocate(s)
+	if cerr := ctx.Err(); cerr != nil {
+		return cerr
+	}
+	if err != nil {
+		return ReallocateError(err)
+	}
+	if r == nil {
+		return ReallocateError(s.p.Tag())
+	}
+	return nil
+}
 
 // A function taking a Skelington instance and returning an error.
 type SkelingtonHook func(*Skelington) error
 
+// A function taking a context and a Skelington instance and returning an error.
+type SkelingtonHookContext func(context.Context, *Skelington) error
+
+// Returns a SkelingtonHook calling the provided SkelingtonHookContext with the
+// context of the Skelington instance it is run on.
+func HookContext(fn SkelingtonHookContext) SkelingtonHook {
+	return func(s *Skelington) error {
+		return fn(s.Context(), s)
+	}
+}
+
 // A type for specifying hook timing.
 type HookTiming int
 
@@ -103,6 +250,9 @@ func (h *hooks) AddHook(t HookTiming, sh ...SkelingtonHook) {
 
 func hookRun(h []SkelingtonHook, s *Skelington) error {
 	for _, fn := range h {
+		if err := s.Context().Err(); err != nil {
+			return err
+		}
 		err := fn(s)
 		if err != nil {
 			return err
@@ -152,6 +302,7 @@ func sortBy(s *Skelington, categories []string) error {
 		nh = append(nh, v...)
 	}
 	s.Has = nh
+	s.Reindex()
 	return nil
 }
 
@@ -164,16 +315,17 @@ func sequenceCategories(m map[string][]Handle) {
 }
 
 // Sets a hook that sets HandleFunc per handle across the entire skeleton, and
-// executing the Call function for all handles in the post add hook.
-func SkelingtonHandleCalls(s *Skelington, hf ...HandleCall) {
-	for _, h := range s.Has {
+// executing the Call function for all handles in the post add hook. Provided a
+// View, only handles of the View are set and called.
+func SkelingtonHandleCalls(s HandleSet, hf ...HandleCall) {
+	for _, h := range s.Handles() {
 		h.SetCall(hf...)
 	}
 	s.AddHook(
 		HPost,
-		func(s *Skelington) error {
+		func(*Skelington) error {
 			var err error
-			for _, h := range s.Has {
+			for _, h := range s.Handles() {
 				err = h.Call()
 				if err != nil {
 					return err
@@ -183,3 +335,43 @@ func SkelingtonHandleCalls(s *Skelington, hf ...HandleCall) {
 		},
 	)
 }
+
+// Sets a hook as SkelingtonHandleCalls for HandleCallContext, providing each call
+// the context of the Skelington instance and stopping on cancellation.
+func SkelingtonHandleCallsContext(s HandleSet, hf ...HandleCallContext) {
+	for _, h := range s.Handles() {
+		h.SetCall(callContext(s, hf...)...)
+	}
+	s.AddHook(
+		HPost,
+		func(*Skelington) error {
+			ctx := s.Context()
+			var err error
+			for _, h := range s.Handles() {
+				if err = ctx.Err(); err != nil {
+					return err
+				}
+				err = h.Call()
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
+
+func callContext(s HandleSet, hf ...HandleCallContext) []HandleCall {
+	var ret []HandleCall
+	for _, fn := range hf {
+		fn := fn
+		ret = append(ret, func(h Handle) error {
+			ctx := s.Context()
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return fn(ctx, h)
+		})
+	}
+	return ret
+}
@@ -2,10 +2,21 @@ package skelington
 
 import (
 	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
 )
 
 type patherExpect struct {
@@ -136,6 +147,19 @@ func compareStats(instance string, t *testing.T, have, expect map[string]int) {
 	}
 }
 
+func fsSkelington(t *testing.T, fsys fs.FS, root, file, allocator string, cnf ...Config) *Skelington {
+	s, err := New(append([]Config{
+		SetRoot(root),
+		SetFile(file),
+		SetFS(fsys),
+		SetAllocator(allocator),
+	}, cnf...)...)
+	if err != nil || s == nil {
+		t.Fatalf("error with %s skeleton instance from %s by %s: %v", root, file, allocator, err)
+	}
+	return s
+}
+
 func testSkelington(expected map[string]int,
 	root, file, allocator, offset string,
 	testEDF bool,
@@ -167,27 +191,536 @@ func coreSkelingtonTest(expected map[string]int,
 	testHandle(t, s)
 	compareStats(allocator, t, haveC, expected)
 	if testEDF {
-		fsErr := toFile(s)
+		_, fsErr := Materialize(s, tmpDir, MaterializeMode(os.ModePerm))
 		if fsErr != nil {
 			t.Errorf("Error transfering to file system: %s", fsErr.Error())
 		}
 	}
 }
 
-func toFile(s *Skelington) error {
+func TestMaterialize(t *testing.T) {
+	fileName := setup(t, tmpDir, "bge.yaml", bgeYaml)
+	dest := filepath.Join(tmpDir, "materialize")
+
+	s, err := New(
+		SetRoot("testMaterialize"),
+		SetFile(fileName),
+		SetAllocator("bge"),
+		SetAllocationOffset("Star"),
+	)
+	if err != nil {
+		t.Errorf("error with materialize skeleton instance: %s", err)
+	}
+	total := len(s.Has)
+
+	b := new(bytes.Buffer)
+	r, err := Materialize(s, dest, MaterializeDryRun(b))
+	if err != nil {
+		t.Errorf("dry run materialize error: %s", err)
+	}
+	if len(r.Created) != total || strings.Count(b.String(), "create ") != total {
+		t.Errorf("dry run should report %d creations, reported %d", total, len(r.Created))
+	}
+	if _, err = os.Stat(dest); !os.IsNotExist(err) {
+		t.Error("dry run materialize should not touch the file system")
+	}
+
+	r, err = Materialize(s, dest, MaterializeMode(0700))
+	if err != nil || len(r.Created) != total {
+		t.Errorf("materialize error, created %d of %d: %v", len(r.Created), total, err)
+	}
+	fi, err := os.Stat(filepath.Join(dest, s.Has[0].Path()))
+	if err != nil || fi.Mode().Perm() != 0700 {
+		t.Errorf("materialized directory mode error: %v %v", fi, err)
+	}
+
+	r, _ = Materialize(s, dest)
+	if len(r.Skipped) != total || len(r.Created) != 0 {
+		t.Errorf("materialize skip expected %d skipped, got %d", total, len(r.Skipped))
+	}
+
+	r, err = Materialize(s, dest, MaterializeConflict(ConflictFail))
+	if err == nil {
+		t.Error("materialize with conflict fail policy should return an error")
+	}
+
+	r, err = Materialize(s, dest, MaterializeConflict(ConflictOverwrite))
+	if err != nil || len(r.Overwritten) != total {
+		t.Errorf("materialize overwrite expected %d overwritten, got %d: %v", total, len(r.Overwritten), err)
+	}
+
+	cleanup(t, tmpDir)
+}
+
+func TestExport(t *testing.T) {
+	fileName := setup(t, tmpDir, "rsp.yaml", rspYaml)
+	exported := filepath.Join(tmpDir, "exported", "rsp.yaml")
+
+	s, err := New(
+		SetRoot("testExport"),
+		SetFile(fileName),
+		SetAllocator("rsp"),
+	)
+	if err != nil {
+		t.Errorf("error with export skeleton instance: %s", err)
+	}
+	err = WriteToFile(exported, LevelFromSkelington(s))
+	if err != nil {
+		t.Errorf("error exporting skeleton: %s", err)
+	}
+	coreSkelingtonTest(rspExp, "testExportRSP", exported, "lit", "", false, t)
+
+	fileName = setup(t, tmpDir, "bge.yaml", bgeYaml)
+	bgeRoot := filepath.Join(tmpDir, "exportBGE")
+	s, _ = New(
+		SetRoot(bgeRoot),
+		SetFile(fileName),
+		SetAllocator("bge"),
+		SetAllocationOffset("Star"),
+	)
+	Materialize(s, "")
+	lv, err := ReadFromDirectory(bgeRoot, DefaultSequencePatternString)
+	if err != nil {
+		t.Errorf("error reading directory for export: %s", err)
+	}
+	exported = filepath.Join(tmpDir, "exported", "bge.yaml")
+	err = WriteToFile(exported, lv)
+	if err != nil {
+		t.Errorf("error exporting directory: %s", err)
+	}
+	coreSkelingtonTest(s.Report(), "testExportBGE", exported, "lit", "", false, t)
+
+	cleanup(t, tmpDir)
+}
+
+var formatYaml = `tag: FORMAT_TEST
+levels:
+  - tag: Car
+    number: 3
+  - tag: Road
+    levels:
+      - tag: Straight
+        number: 2
+      - tag: Curved
+        number: 1`
+
+var formatJSON = `{
+  "tag": "FORMAT_TEST",
+  "levels": [
+    {"tag": "Car", "number": 3},
+    {"tag": "Road", "levels": [
+      {"tag": "Straight", "number": 2},
+      {"tag": "Curved", "number": 1}
+    ]}
+  ]
+}`
+
+var formatTOML = `# a toml specification
+tag = "FORMAT_TEST"
+
+[[levels]]
+tag = "Car"
+number = 3
+
+[[levels]]
+tag = "Road"
+
+  [[levels.levels]]
+  tag = "Straight"
+  number = 2
+
+  [[levels.levels]]
+  tag = "Curved"
+  number = 1`
+
+var formatExp = map[string]int{
+	"TOTAL":    6,
+	"CAR":      3,
+	"STRAIGHT": 2,
+	"CURVED":   1,
+}
+
+func TestSpecFormat(t *testing.T) {
+	fsys := fstest.MapFS{
+		"format.yaml": {Data: []byte(formatYaml)},
+		"format.json": {Data: []byte(formatJSON)},
+		"format.toml": {Data: []byte(formatTOML)},
+		"yaml.spec":   {Data: []byte(formatYaml)},
+		"json.spec":   {Data: []byte(formatJSON)},
+		"toml.spec":   {Data: []byte(formatTOML)},
+		"forced.yaml": {Data: []byte(formatTOML)},
+	}
+	for name := range fsys {
+		if name == "forced.yaml" {
+			continue
+		}
+		s := fsSkelington(t, fsys, "testFormat", name, "lit")
+		compareStats(name, t, s.Report(), formatExp)
+	}
+
+	s := fsSkelington(t, fsys, "testFormat", "forced.yaml", "lit", SetSpecFormat("toml"))
+	compareStats("forced", t, s.Report(), formatExp)
+
+	_, err := New(SetRoot("testFormat"), SetSpecFormat("xml"))
+	if err == nil {
+		t.Error("expected configuration error for an unknown specification format")
+	}
+}
+
+func TestReadFrom(t *testing.T) {
+	lv, err := ReadFrom(strings.NewReader(formatJSON))
+	if err != nil {
+		t.Errorf("error reading specification from reader: %s", err)
+	}
+	if lv.Tag != "FORMAT_TEST" || len(lv.Levels) != 2 {
+		t.Errorf("unexpected level read from reader: %v", lv)
+	}
+
+	s, err := New(
+		SetRoot("testReader"),
+		SetFileReader(strings.NewReader(formatTOML)),
+		SetAllocator("lit"),
+	)
+	if err != nil {
+		t.Errorf("error with reader skeleton instance: %s", err)
+	}
+	compareStats("reader", t, s.Report(), formatExp)
+	if err = s.Reallocate(); err != nil {
+		t.Errorf("error reallocating reader skeleton instance: %s", err)
+	}
+	compareStats("reader reallocated", t, s.Report(), formatExp)
+
+	fsys := fstest.MapFS{
+		"tree/Car/1-of-3/.keep":        {},
+		"tree/Car/2-of-3/.keep":        {},
+		"tree/Car/3-of-3/.keep":        {},
+		"tree/Road/Straight/1-of-2":    {Mode: fs.ModeDir},
+		"tree/Road/Straight/2-of-2":    {Mode: fs.ModeDir},
+		"tree/Road/Curved/1-of-1/file": {Data: []byte("ignored")},
+	}
+	lv, err = ReadFromFS(fsys, "tree", DefaultSequencePatternString)
+	if err != nil {
+		t.Errorf("error reading level from file system: %s", err)
+	}
+	if lv.Tag != "tree" || len(lv.Levels) != 2 {
+		t.Errorf("unexpected level read from file system: %v", lv)
+	}
+
+	s, err = New(
+		SetRoot("tree"),
+		SetFS(fsys),
+		SetAllocator("edf"),
+		SetAllocationOffset(DefaultSequencePatternString),
+	)
+	if err != nil {
+		t.Errorf("error with file system skeleton instance: %s", err)
+	}
+	compareStats("fs", t, s.Report(), formatExp)
+}
+
+func TestConcurrentHandleCalls(t *testing.T) {
+	fileName := setup(t, tmpDir, "bge.yaml", bgeYaml)
+
+	s, err := New(
+		SetRoot("testConcurrent"),
+		SetFile(fileName),
+		SetAllocator("bge"),
+		SetAllocationOffset("Star"),
+	)
+	if err != nil {
+		t.Errorf("error with concurrent skeleton instance: %s", err)
+	}
+
+	var mu sync.Mutex
+	called := make(map[string][]string)
+	SkelingtonConcurrentHandleCalls(s, 4, UnitOrdered,
+		func(hh Handle) error {
+			u := hh.Unit()
+			mu.Lock()
+			called[u.Value] = append(called[u.Value], hh.Key())
+			mu.Unlock()
+			if u.Value == "Rocks" {
+				return errors.New("rocks")
+			}
+			return nil
+		},
+	)
+
+	var reported []string
+	for i := 0; i < 2; i++ {
+		called = make(map[string][]string)
+		err = s.RunHook(HPost)
+		ce, ok := err.(CallErrors)
+		if !ok || len(ce) != 30 {
+			t.Errorf("expected 30 call errors, got %v", err)
+			continue
+		}
+		var paths []string
+		for _, e := range ce {
+			paths = append(paths, e.Handle.Path())
+		}
+		if reported != nil && strings.Join(paths, "") != strings.Join(reported, "") {
+			t.Error("call errors should be reported deterministically")
+		}
+		reported = paths
+	}
+
+	ordered := make(map[string][]string)
 	for _, h := range s.Has {
-		mkPath := filepath.Join(tmpDir, h.Path())
-		fsErr := os.MkdirAll(mkPath, os.ModeDir|os.ModePerm)
-		if fsErr != nil {
-			return fsErr
+		u := h.Unit()
+		ordered[u.Value] = append(ordered[u.Value], h.Key())
+	}
+	for k, v := range ordered {
+		if strings.Join(v, "") != strings.Join(called[k], "") {
+			t.Errorf("handles of unit %s were not called in order", k)
 		}
 	}
+	cleanup(t, tmpDir)
+}
+
+// A context done once Err has been called n times.
+type countdownContext struct {
+	context.Context
+	n int
+}
+
+func (c *countdownContext) Err() error {
+	if c.n--; c.n < 0 {
+		return context.Canceled
+	}
 	return nil
 }
 
+func TestContext(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bge.yaml":        {Data: []byte(bgeYaml)},
+		"tree/Car/1-of-1": {Mode: fs.ModeDir},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewContext(ctx, SetRoot("testContext"))
+	if err != context.Canceled {
+		t.Errorf("expected context canceled from a done context, got %v", err)
+	}
+	_, err = ReadFromFS(fsys, "tree", DefaultSequencePatternString, ReadContext(ctx))
+	if err != context.Canceled {
+		t.Errorf("expected context canceled from directory walk, got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	s, err := NewContext(ctx,
+		SetRoot("testContext"),
+		SetFile("bge.yaml"),
+		SetFS(fsys),
+		SetAllocator("bge"),
+		SetHookContext(HBefore, func(ctx context.Context, s *Skelington) error {
+			cancel()
+			return nil
+		}),
+	)
+	if s != nil || err != context.Canceled {
+		t.Errorf("expected allocation to stop on cancellation, got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	var called int
+	_, err = NewContext(ctx,
+		SetRoot("testContext"),
+		SetFile("bge.yaml"),
+		SetFS(fsys),
+		SetAllocator("bge"),
+		SetAllocationOffset("Star"),
+		SetHookContext(HAfter, func(ctx context.Context, s *Skelington) error {
+			SkelingtonHandleCallsContext(s, func(ctx context.Context, h Handle) error {
+				called++
+				if called == 10 {
+					cancel()
+				}
+				return nil
+			})
+			for _, h := range s.Has {
+				if err := h.Call(); err != nil {
+					return err
+				}
+			}
+			return nil
+		}),
+	)
+	if err != context.Canceled || called != 10 {
+		t.Errorf("expected handle calls to stop on cancellation after 10, got %d: %v", called, err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	s, err = NewContext(ctx,
+		SetRoot("testContext"),
+		SetFile("bge.yaml"),
+		SetFS(fsys),
+		SetAllocator("bge"),
+		SetAllocationOffset("Star"),
+	)
+	cancel()
+	if err != nil || s == nil {
+		t.Fatalf("error with context skeleton instance: %v", err)
+	}
+	if err = s.Context().Err(); err != nil {
+		t.Errorf("expected the context to govern only allocation, got %v", err)
+	}
+	if err = s.Add(newHandle(&Sequence{1, 1}, &Tag{0, "testContext"}, nil, &Tag{1, "Added"}, nil)); err != nil {
+		t.Errorf("error adding after allocation context is done: %v", err)
+	}
+	if err = s.Reallocate(); err != nil {
+		t.Errorf("error reallocating after allocation context is done: %v", err)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err = s.ReallocateContext(ctx); err != context.Canceled {
+		t.Errorf("expected reallocation with a done context to fail, got %v", err)
+	}
+	if err = s.RunHook(HPost); err != nil {
+		t.Errorf("error running hooks after reallocation context is done: %v", err)
+	}
+
+	lv := &Level{Tag: "Star", Leaf: true, Number: 1}
+	c := &Skelington{ctx: &countdownContext{context.Background(), 10}}
+	var yielded int
+	for range sequenced(c, &Tag{0, "testContext"}, []allotment{{lv, 100000}}) {
+		yielded++
+	}
+	if yielded != 10 {
+		t.Errorf("expected a single allotment to stop when the context is done, yielded %d", yielded)
+	}
+}
+
+// An Allocator without a streaming strategy.
+type batchAllocator struct {
+	Allocator
+}
+
+func TestStream(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bge.yaml": {Data: []byte(bgeYaml)},
+	}
+
+	s, seq, err := NewStream(
+		SetRoot("testStream"),
+		SetFile("bge.yaml"),
+		SetFS(fsys),
+		SetAllocator("bge"),
+	)
+	if err != nil {
+		t.Errorf("error with stream skeleton instance: %s", err)
+	}
+	units := make(map[string]int)
+	var streamed int
+	for h, err := range seq {
+		if err != nil {
+			t.Fatalf("error streaming handles: %v", err)
+		}
+		streamed++
+		u := h.Unit()
+		sq := h.Sequence()
+		units[u.Value] = units[u.Value] + 1
+		if sq.Number != units[u.Value] || sq.Number > sq.Count {
+			t.Errorf("streamed handle out of sequence: %s", h.Path())
+		}
+		if len(s.Has) != 1 {
+			t.Errorf("stream skeleton should hold only the current handle, holds %d", len(s.Has))
+		}
+	}
+	if streamed != 3302 || len(s.Has) != 0 {
+		t.Errorf("expected 3302 streamed handles and none retained, got %d and %d", streamed, len(s.Has))
+	}
+	compareStats("stream", t, s.Report(), bgeExp)
+
+	s, seq, _ = NewStream(
+		SetRoot("testStream"),
+		SetFile("bge.yaml"),
+		SetFS(fsys),
+		SetAllocator("bge"),
+	)
+	streamed = 0
+	for range seq {
+		streamed++
+		if streamed == 10 {
+			break
+		}
+	}
+	if tot := s.Report()["TOTAL"]; tot != 10 {
+		t.Errorf("expected a stopped stream to report 10 handles, reported %d", tot)
+	}
+
+	fsys["invalid.yaml"] = &fstest.MapFile{Data: []byte(invalidYaml)}
+	_, seq, err = NewStream(
+		SetRoot("testStream"),
+		SetFile("invalid.yaml"),
+		SetFS(fsys),
+		SetAllocator("bge"),
+		SetValidation(),
+	)
+	if err != nil {
+		t.Fatalf("error with invalid stream skeleton instance: %s", err)
+	}
+	var errs []error
+	for h, err := range seq {
+		if h != nil || err == nil {
+			t.Errorf("expected an invalid stream to yield only an error, got %v", h)
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("expected an invalid stream to yield one error, got %d", len(errs))
+	}
+	if _, ok := errs[0].(SpecErrors); !ok {
+		t.Errorf("expected an invalid stream to yield SpecErrors, got %v", errs[0])
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p, err := newProcessor(
+		SetRoot("testStream"),
+		SetFile("bge.yaml"),
+		SetFS(fsys),
+		SetAllocator("lit"),
+	)
+	if err != nil {
+		t.Fatalf("error with stream processor: %s", err)
+	}
+	p.Allocator = batchAllocator{p.Allocator}
+	s, seq = p.StreamContext(ctx)
+	streamed = 0
+	var last error
+	for _, err := range seq {
+		if err != nil {
+			last = err
+			continue
+		}
+		streamed++
+		cancel()
+	}
+	if streamed == 0 || last != context.Canceled || len(s.Has) != 0 {
+		t.Errorf("expected a fallback stream to yield handles then the context error, retaining none, got %d, %v and %d", streamed, last, len(s.Has))
+	}
+}
+
 func TestEMPAllocator(t *testing.T) {
 	exp := map[string]int{}
 	testSkelington(exp, "test", "", "", "", false, t)
+
+	s, err := New(SetRoot("testEMP"))
+	if err != nil || s == nil {
+		t.Fatalf("error with emp skeleton instance: %v", err)
+	}
+	for _, u := range []string{"A", "B"} {
+		if err = s.Add(newHandle(&Sequence{1, 1}, &Tag{0, "testEMP"}, nil, &Tag{1, u}, nil)); err != nil {
+			t.Fatalf("error adding to emp skeleton instance: %v", err)
+		}
+	}
+	for _, u := range []string{"A", "B"} {
+		if n := s.Report()[u]; n > 1 {
+			t.Errorf("expected %s to be counted at most once by adding, got %d", u, n)
+		}
+	}
 }
 
 var rspYaml = `---
@@ -328,61 +861,1350 @@ levels:
         - tag: Dust
           number: 10`
 
-func TestBGEAllocator(t *testing.T) {
-	fileName := setup(t, tmpDir, "bge.yaml", bgeYaml)
+var bgeExp = map[string]int{
+	"DUST":         200,
+	"STAR":         40,
+	"TOTAL":        3302,
+	"TYPE4":        20,
+	"GALAXY":       20,
+	"ROCKS":        1200,
+	"TREES":        1200,
+	"TYPE1":        100,
+	"TYPE2":        40,
+	"CIVILIZATION": 120,
+	"TYPE3":        40,
+	"PLANET":       120,
+	"ASTEROID":     200,
+	"UNIVERSE":     2,
+}
 
-	exp := map[string]int{
-		"DUST":         200,
-		"STAR":         40,
-		"TOTAL":        3302,
-		"TYPE4":        20,
-		"GALAXY":       20,
-		"ROCKS":        1200,
-		"TREES":        1200,
-		"TYPE1":        100,
-		"TYPE2":        40,
-		"CIVILIZATION": 120,
-		"TYPE3":        40,
-		"PLANET":       120,
-		"ASTEROID":     200,
-		"UNIVERSE":     2,
+var roundingYaml = `tag: ROUNDING_TEST
+number: 40
+rounding: preserve
+levels:
+  - tag: Hole
+    number: 13
+  - tag: Pile
+    number: 13
+  - tag: Cow
+    number: 13`
+
+func TestRounding(t *testing.T) {
+	fsys := fstest.MapFS{
+		"rsp.yaml":      {Data: []byte(rspYaml)},
+		"rounding.yaml": {Data: []byte(roundingYaml)},
+		"hamilton.yaml": {Data: []byte(strings.Replace(rspYaml, "number: 100", "number: 100\nrounding: hamilton", 1))},
+	}
+	rounded := func(file, rounding string) map[string]int {
+		s := fsSkelington(t, fsys, "testRounding", file, "rsp",
+			SetRounding(rounding),
+		)
+		return s.Report()
 	}
 
-	exp0 := map[string]int{
-		"TOTAL":        67,
-		"ROCKS":        30,
-		"TREES":        30,
-		"CIVILIZATION": 3,
-		"PLANET":       3,
-		"STAR":         1,
+	compareStats("floor", t, rounded("rsp.yaml", "floor"), rspExp)
+
+	hamiltonExp := map[string]int{
+		"TOTAL":        100,
+		"CAR":          7,
+		"STRAIGHT":     5,
+		"CURVED":       4,
+		"HOLE":         13,
+		"PILE":         13,
+		"COW":          13,
+		"BABYCARRIAGE": 1,
+		"FAST":         7,
+		"MISSLE":       7,
+		"STAR":         6,
+		"KILL":         2,
+		"SLOW":         2,
+		"STOP":         2,
+		"OILSLICK":     6,
+		"TELEPORT":     6,
+		"OTHER":        6,
 	}
+	compareStats("hamilton", t, rounded("rsp.yaml", "hamilton"), hamiltonExp)
+	compareStats("largest-remainder", t, rounded("rsp.yaml", "largest-remainder"), hamiltonExp)
+	compareStats("hamilton spec", t, rounded("hamilton.yaml", ""), hamiltonExp)
+	compareStats("preserve", t, rounded("rsp.yaml", "preserve"), hamiltonExp)
+	compareStats("nearest", t, rounded("rsp.yaml", "nearest"), map[string]int{"TOTAL": 99, "STRAIGHT": 5, "CURVED": 5})
 
-	testSkelington(exp, "testBGE", fileName, "bge", "", true, t)
-	testSkelington(exp0, "testBGEOffset", fileName, "bge", "Star", true, t)
-	cleanup(t, tmpDir)
+	compareStats("preserve spec", t, rounded("rounding.yaml", "hamilton"), map[string]int{
+		"TOTAL": 40,
+		"HOLE":  14,
+		"PILE":  13,
+		"COW":   13,
+	})
+
+	_, err := New(SetRoot("testRounding"), SetRounding("ceiling"))
+	if err == nil {
+		t.Error("expected configuration error for an unknown rounding")
+	}
 }
 
-/*
-var currStats map[string]int
+var constraintYaml = `tag: CONSTRAINT_TEST
+number: 10
+levels:
+  - tag: Hole
+    number: 5
+  - tag: Cow
+    number: 4
+    max: 2
+  - tag: BabyCarriage
+    min: 1`
 
-func skelingtonCallsStatistics(s *Skelington, once []StatFunc, every []StatFunc) {
-	cs := newStat(s)
-	for _, h := range s.Has {
-		t := h.Unit()
-		tag := strings.ToUpper(t.Value)
-		once = append(once, func(s *Skelington, m map[string]int) error {
-			cs.d[tag] = cs.d[tag] + 1
-			return nil
-		})
+var constraintBGEYaml = `tag: CONSTRAINT_TEST
+levels:
+  - tag: Galaxy
+    leaf: true
+    number: 10
+    max: 3
+    levels:
+      - tag: Star
+        number: 2
+        exact: 0
+      - tag: Dust
+        number: 1
+        min: 4`
+
+var unsatisfiableYaml = `tag: UNSATISFIABLE_TEST
+number: 10
+levels:
+  - tag: Hole
+    number: 5
+    exact: 5
+  - tag: Cow
+    number: 5
+    min: 6`
+
+func TestConstraints(t *testing.T) {
+	fsys := fstest.MapFS{
+		"rsp.yaml": {Data: []byte(constraintYaml)},
+		"bge.yaml": {Data: []byte(constraintBGEYaml)},
+	}
+	for _, v := range []struct {
+		file, allocator string
+		exp             map[string]int
+	}{
+		{"rsp.yaml", "rsp", map[string]int{"TOTAL": 9, "HOLE": 6, "COW": 2, "BABYCARRIAGE": 1}},
+		{"bge.yaml", "bge", map[string]int{"TOTAL": 15, "GALAXY": 3, "DUST": 12}},
+	} {
+		s := fsSkelington(t, fsys, "testConstraints", v.file, v.allocator)
+		compareStats(v.allocator, t, s.Report(), v.exp)
+		if _, ok := s.Report()["STAR"]; ok {
+			t.Error("a level with an exact constraint of 0 should have no handles")
+		}
 	}
-	cs.Set("once", once...)
 
-	s.AddHook(
-		HPost,
-		func(s *Skelington) error {
-			err := cs.Run(s, "once")
-			currStats = cs.Report()
-			return err
-		})
+	lv, err := ReadFrom(strings.NewReader(unsatisfiableYaml))
+	if err != nil {
+		t.Errorf("error reading unsatisfiable specification: %s", err)
+	}
+	_, err = rspAllot(&Skelington{}, lv, "")
+	if err == nil || !strings.Contains(err.Error(), "UNSATISFIABLE_TEST") {
+		t.Errorf("expected an unsatisfiable constraint error, got %v", err)
+	}
+
+	for _, v := range []struct {
+		spec, allocator, expect string
+	}{
+		{unsatisfiableYaml, "rsp", "UNSATISFIABLE_TEST"},
+		{"tag: UNSATISFIABLE_TEST\nlevels:\n  - tag: Cow\n    number: 3\n    min: 5\n    max: 2", "bge", "UNSATISFIABLE_TEST/Cow: min 5 exceeds max 2"},
+		{"tag: UNSATISFIABLE_TEST\nlevels:\n  - tag: Road\n    max: 2\n    levels:\n      - tag: Cow\n        number: 3", "bge", "UNSATISFIABLE_TEST/Road: only a leaf"},
+	} {
+		s, err := New(
+			SetRoot("testConstraint"),
+			SetFileReader(strings.NewReader(v.spec)),
+			SetAllocator(v.allocator),
+		)
+		if err == nil || s != nil || !strings.Contains(err.Error(), v.expect) {
+			t.Errorf("expected New to return an unsatisfiable %s constraint error containing %q, got %v", v.allocator, v.expect, err)
+		}
+	}
+}
+
+var invalidYaml = `tag: INVALID_TEST
+number: 100
+levels:
+  - tag: Car
+    number: -7
+  - tag: Road
+    relative: true
+    number: 80
+  - tag: Road
+    relative: true
+    number: 40
+  - tag: Obstacle
+    leaf: true
+    levels:
+      - tag: Cow
+        min: 5
+        max: 2
+        rounding: ceiling`
+
+func TestValidate(t *testing.T) {
+	fsys := fstest.MapFS{
+		"invalid.yaml":   {Data: []byte(invalidYaml)},
+		"rsp.yaml":       {Data: []byte(rspYaml)},
+		"bge.yaml":       {Data: []byte(bgeYaml)},
+		"prototype.yaml": {Data: []byte("tag: PROTOTYPE_TEST\nprototypes:\n  Planet:\n    number: -2\n    levels:\n      - tag: Moon\n        number: -1\nlevels:\n  - use: Planet\n  - use: Planet\n    tag: Rock\n")},
+	}
+
+	for _, valid := range []string{"rsp.yaml", "bge.yaml"} {
+		if err := ValidateFile(valid, ReadFS(fsys)); err != nil {
+			t.Errorf("expected %s to be valid: %s", valid, err)
+		}
+	}
+
+	err := ValidateFile("invalid.yaml", ReadFS(fsys))
+	se, ok := err.(SpecErrors)
+	if !ok {
+		t.Fatalf("expected SpecErrors, got %v", err)
+	}
+	expect := []string{
+		"invalid.yaml:3:1:INVALID_TEST: relative numbers of children sum to 120, over 100",
+		"invalid.yaml:5:5:INVALID_TEST/Car: negative number -7",
+		"invalid.yaml:9:5:INVALID_TEST/Road: duplicate sibling tag Road",
+		"invalid.yaml:13:5:INVALID_TEST/Obstacle: leaf with no number",
+		"invalid.yaml:16:9:INVALID_TEST/Obstacle/Cow: min 5 exceeds max 2",
+		"invalid.yaml:18:9:INVALID_TEST/Obstacle/Cow: unknown rounding ceiling",
+	}
+	if len(se) != len(expect) {
+		t.Errorf("expected %d specification errors, got %d:\n%s", len(expect), len(se), se)
+	}
+	for i := 0; i < len(se) && i < len(expect); i++ {
+		if se[i].Error() != expect[i] {
+			t.Errorf("specification error mismatch: expected %q, got %q", expect[i], se[i].Error())
+		}
+	}
+
+	err = ValidateFile("prototype.yaml", ReadFS(fsys))
+	if se, ok = err.(SpecErrors); !ok {
+		t.Fatalf("expected SpecErrors for prototypes, got %v", err)
+	}
+	expect = []string{
+		"prototype.yaml:4:5:PROTOTYPE_TEST/Planet: negative number -2",
+		"prototype.yaml:4:5:PROTOTYPE_TEST/Rock: negative number -2",
+		"prototype.yaml:7:9:PROTOTYPE_TEST/Planet/Moon: negative number -1",
+		"prototype.yaml:7:9:PROTOTYPE_TEST/Rock/Moon: negative number -1",
+	}
+	if len(se) != len(expect) {
+		t.Errorf("expected %d prototype specification errors, got %d:\n%s", len(expect), len(se), se)
+	}
+	for i := 0; i < len(se) && i < len(expect); i++ {
+		if se[i].Error() != expect[i] {
+			t.Errorf("prototype specification error mismatch: expected %q, got %q", expect[i], se[i].Error())
+		}
+	}
+
+	s, err := New(
+		SetRoot("testValidate"),
+		SetFile("invalid.yaml"),
+		SetFS(fsys),
+		SetAllocator("rsp"),
+		SetValidation(),
+	)
+	if _, ok = err.(SpecErrors); !ok || s != nil {
+		t.Errorf("expected SpecErrors allocating an invalid specification, got %v", err)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected allocation of an invalid specification to fail validation")
+		}
+	}()
+	New(
+		SetRoot("testValidate"),
+		SetFile("invalid.yaml"),
+		SetFS(fsys),
+		SetAllocator("rsp"),
+		SetValidation(),
+		SetError("panic"),
+	)
+}
+
+var includeYaml = `tag: INCLUDE_TEST
+levels:
+  - tag: Car
+    number: 3
+  - include: parts/powerups.yaml
+  - $ref: parts/powerups.yaml#PowerUps/Laser`
+
+var includedYaml = `tag: PowerUps
+levels:
+  - tag: Fast
+    number: 2
+  - tag: Laser
+    levels:
+      - tag: Kill
+        number: 1
+      - $ref: "#Fast"`
+
+func TestInclude(t *testing.T) {
+	fsys := fstest.MapFS{
+		"include.yaml":        {Data: []byte(includeYaml)},
+		"parts/powerups.yaml": {Data: []byte(includedYaml)},
+		"cycle.yaml":          {Data: []byte("tag: CYCLE\nlevels:\n  - include: parts/cycle.yaml")},
+		"parts/cycle.yaml":    {Data: []byte("tag: CYCLE\nlevels:\n  - include: ../cycle.yaml")},
+		"self.yaml":           {Data: []byte("tag: SELF\nlevels:\n  - tag: A\n    levels:\n      - $ref: \"#A\"")},
+		"missing.yaml":        {Data: []byte("tag: MISSING\nlevels:\n  - $ref: \"#Nothing\"")},
+	}
+
+	s := fsSkelington(t, fsys, "testInclude", "include.yaml", "lit")
+	compareStats("include", t, s.Report(), map[string]int{
+		"TOTAL": 11,
+		"CAR":   3,
+		"FAST":  6,
+		"KILL":  2,
+	})
+	paths := make(map[string]bool)
+	for _, h := range s.Has {
+		paths[filepath.Dir(h.Path())] = true
+	}
+	for _, p := range []string{
+		"testInclude/INCLUDE_TEST/PowerUps/Fast",
+		"testInclude/INCLUDE_TEST/PowerUps/Laser/Fast",
+		"testInclude/INCLUDE_TEST/Laser/Fast",
+	} {
+		if !paths[p] {
+			t.Errorf("expected included handles at %s", p)
+		}
+	}
+
+	for _, bad := range []string{"cycle.yaml", "self.yaml", "missing.yaml"} {
+		_, err := ReadFromFile(bad, ReadFS(fsys))
+		if err == nil {
+			t.Errorf("expected include error reading %s", bad)
+		}
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "typo.yaml"), []byte("tag: TYPO_TEST\nlevels:\n  - include: parts/typo.yaml"), 0660); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadFromFile(filepath.Join(dir, "typo.yaml")); err == nil {
+		t.Error("expected include error reading a missing file from the os")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "parts")); !os.IsNotExist(err) {
+		t.Errorf("expected a missing include to not be created, got %v", err)
+	}
+
+	fsys["override.yaml"] = &fstest.MapFile{Data: []byte("tag: OVERRIDE\nlevels:\n  - include: parts/powerups.yaml\n    tag: Boosts\n    relative: true\n    number: 40\n    rounding: nearest\n  - $ref: \"#Boosts/Laser\"\n    number: 3")}
+	lv, err := ReadFromFile("override.yaml", ReadFS(fsys))
+	if err != nil {
+		t.Fatalf("error reading include with overrides: %v", err)
+	}
+	b := lv.Levels[0]
+	if b.Tag != "Boosts" || !b.Relative || b.Number != 40 || b.Rounding != "nearest" || len(b.Levels) == 0 || b.Levels[0].Tag != "Fast" {
+		t.Errorf("expected included level with overrides, got %+v", b)
+	}
+	if l := lv.Levels[1]; l.Tag != "Laser" || l.Number != 3 {
+		t.Errorf("expected referenced level with overrides, got %+v", l)
+	}
+}
+
+func TestSSPAllocator(t *testing.T) {
+	fsys := fstest.MapFS{
+		"rsp.yaml": {Data: []byte(rspYaml)},
+	}
+	seeded := func(seed int64) *Skelington {
+		s := fsSkelington(t, fsys, "testSSP", "rsp.yaml", "ssp",
+			SetSeed(seed),
+		)
+		return s
+	}
+
+	s := seeded(42)
+	exp := make(map[string]int)
+	for k, v := range s.Report() {
+		exp[k] = v
+	}
+	if s.Seed() != 42 {
+		t.Errorf("expected the seed 42, got %d", s.Seed())
+	}
+	if exp[SeedStatistic] != 42 {
+		t.Errorf("expected statistics to report the seed 42, reported %d", exp[SeedStatistic])
+	}
+	if exp["TOTAL"] == 0 || exp["TOTAL"] > 100 {
+		t.Errorf("unexpected total for ssp allocation: %d", exp["TOTAL"])
+	}
+	compareStats("ssp same seed", t, seeded(42).Report(), exp)
+	if err := s.Reallocate(); err != nil {
+		t.Errorf("error reallocating ssp skeleton instance: %s", err)
+	}
+	compareStats("ssp reallocated", t, s.Report(), exp)
+
+	var varied bool
+	for seed := int64(1); seed < 10 && !varied; seed++ {
+		have := seeded(seed).Report()
+		for k, v := range exp {
+			if k != SeedStatistic && have[k] != v {
+				varied = true
+			}
+		}
+	}
+	if !varied {
+		t.Error("expected different seeds to produce different allocations")
+	}
+
+	s = fsSkelington(t, fsys, "testSSP", "rsp.yaml", "ssp")
+	if s.Seed() == 0 || s.Report()[SeedStatistic] != int(s.Seed()) {
+		t.Errorf("expected an unseeded ssp allocation to report the seed drawn, reported %d", s.Report()[SeedStatistic])
+	}
+	if _, ok := Diff(seeded(1), seeded(2)).Tags[SeedStatistic]; ok {
+		t.Error("expected a difference to skip the seed reported")
+	}
+	compareStats("ssp drawn seed", t, seeded(s.Seed()).Report(), s.Report())
+}
+
+func TestBGEAllocator(t *testing.T) {
+	fileName := setup(t, tmpDir, "bge.yaml", bgeYaml)
+
+	exp := bgeExp
+
+	exp0 := map[string]int{
+		"TOTAL":        67,
+		"ROCKS":        30,
+		"TREES":        30,
+		"CIVILIZATION": 3,
+		"PLANET":       3,
+		"STAR":         1,
+	}
+
+	testSkelington(exp, "testBGE", fileName, "bge", "", true, t)
+	testSkelington(exp0, "testBGEOffset", fileName, "bge", "Star", true, t)
+	cleanup(t, tmpDir)
+}
+
+/*
+var currStats map[string]int
+
+func skelingtonCallsStatistics(s *Skelington, once []StatFunc, every []StatFunc) {
+	cs := newStat(s)
+	for _, h := range s.Has {
+		t := h.Unit()
+		tag := strings.ToUpper(t.Value)
+		once = append(once, func(s *Skelington, m map[string]int) error {
+			cs.d[tag] = cs.d[tag] + 1
+			return nil
+		})
+	}
+	cs.Set("once", once...)
+
+	s.AddHook(
+		HPost,
+		func(s *Skelington) error {
+			err := cs.Run(s, "once")
+			currStats = cs.Report()
+			return err
+		})
+}
+*/
+
+func TestSkelingtonLifecycle(t *testing.T) {
+	fileName := setup(t, tmpDir, "rsp.yaml", rspYaml)
+
+	s, err := New(
+		SetRoot("testLifecycle"),
+		SetFile(fileName),
+		SetAllocator("rsp"),
+	)
+	if err != nil {
+		t.Errorf("error with lifecycle skeleton instance: %s", err)
+	}
+	hooked := len(s.Hooks.(*hooks).m[HPost])
+
+	s.Clear()
+	if len(s.Has) != 0 {
+		t.Errorf("cleared skeleton should have no handles, has %d", len(s.Has))
+	}
+
+	s.Reset()
+	if tot := s.Report()["TOTAL"]; tot != 0 {
+		t.Errorf("reset skeleton should have a zero TOTAL, has %d", tot)
+	}
+
+	for i := 0; i < 3; i++ {
+		err = s.Reallocate()
+		if err != nil {
+			t.Errorf("reallocation error: %s", err)
+		}
+		compareStats("reallocate", t, s.Report(), rspExp)
+		if rh := len(s.Hooks.(*hooks).m[HPost]); rh != hooked {
+			t.Errorf("reallocation should not accumulate hooks: expected %d, got %d", hooked, rh)
+		}
+	}
+
+	b := &Skelington{}
+	if err = b.Reallocate(); err == nil {
+		t.Error("expected error reallocating a skeleton without a processor")
+	}
+
+	boom := errors.New("boom")
+	for _, hook := range []Config{
+		SetHook(HPost, func(*Skelington) error { return boom }),
+		SetHookContext(HAfter, func(context.Context, *Skelington) error { return boom }),
+	} {
+		s, err = New(
+			SetRoot("testLifecycle"),
+			SetFile(fileName),
+			SetAllocator("rsp"),
+			hook,
+		)
+		if err != boom || s != nil {
+			t.Errorf("expected New to return the hook error, got %v", err)
+		}
+	}
+	_, seq, err := NewStream(
+		SetRoot("testLifecycle"),
+		SetFile(fileName),
+		SetAllocator("rsp"),
+		SetHook(HBefore, func(*Skelington) error { return boom }),
+	)
+	if err != nil {
+		t.Errorf("error with lifecycle stream: %s", err)
+	}
+	for h, err := range seq {
+		if h != nil || err != boom {
+			t.Errorf("expected a stream to yield only the hook error, got %v", err)
+		}
+	}
+	cleanup(t, tmpDir)
+}
+
+func TestLITAllocator(t *testing.T) {
+	fileName := setup(t, tmpDir, "lit.yaml", bgeYaml)
+
+	exp := map[string]int{
+		"DUST":         10,
+		"STAR":         2,
+		"TOTAL":        68,
+		"TYPE4":        1,
+		"GALAXY":       10,
+		"ROCKS":        10,
+		"TREES":        10,
+		"TYPE1":        5,
+		"TYPE2":        2,
+		"CIVILIZATION": 1,
+		"TYPE3":        2,
+		"PLANET":       3,
+		"ASTEROID":     10,
+		"UNIVERSE":     2,
+	}
+
+	exp0 := map[string]int{
+		"TOTAL":        26,
+		"ROCKS":        10,
+		"TREES":        10,
+		"CIVILIZATION": 1,
+		"PLANET":       3,
+		"STAR":         2,
+	}
+
+	testSkelington(exp, "testLIT", fileName, "lit", "", true, t)
+	testSkelington(exp0, "testLITOffset", fileName, "lit", "Star", true, t)
+	cleanup(t, tmpDir)
+}
+
+var templateYaml = `
+defaults:
+  cars: 3
+  region: North
+tag: TEMPLATE_TEST
+levels:
+  - tag: Car
+    number: ${cars}
+  - tag: ${region}-Road
+    number: ${roads}
+  - tag: $${Literal}
+    number: 1
+`
+
+var templateJSON = `{"tag": "TEMPLATE_TEST", "levels": [{"tag": "Car", "number": ${cars}}]}`
+
+func TestTemplate(t *testing.T) {
+	fsys := fstest.MapFS{
+		"template.yaml": {Data: []byte(templateYaml)},
+		"template":      {Data: []byte(templateJSON)},
+	}
+
+	lv, err := ReadFromFile("template", ReadFS(fsys), ReadVariables(map[string]string{"cars": "4"}))
+	if err != nil || lv.Levels[0].Number != 4 {
+		t.Errorf("error rendering json template: %v", err)
+	}
+
+	_, err = ReadFromFile("template.yaml", ReadFS(fsys))
+	if err == nil {
+		t.Error("expected error rendering template with undefined variable")
+	}
+
+	t.Setenv("cars", "5")
+	lv, err = ReadFromFile(
+		"template.yaml",
+		ReadFS(fsys),
+		ReadVariables(map[string]string{"roads": "2", "region": "South"}),
+	)
+	if err != nil {
+		t.Fatalf("error rendering yaml template: %v", err)
+	}
+	for i, exp := range []struct {
+		tag    string
+		number int
+	}{
+		{"Car", 5},
+		{"South-Road", 2},
+	} {
+		if lv.Levels[i].Tag != exp.tag || lv.Levels[i].Number != exp.number {
+			t.Errorf("expected %s %d, got %s %d", exp.tag, exp.number, lv.Levels[i].Tag, lv.Levels[i].Number)
+		}
+	}
+	if lv.Levels[2].Tag != "${Literal}" {
+		t.Errorf("expected escaped placeholder, got %s", lv.Levels[2].Tag)
+	}
+}
+
+var prototypeYaml = `
+tag: PROTOTYPE_TEST
+prototypes:
+  Planet:
+    number: 2
+    levels:
+      - tag: Moon
+        number: 1
+  Giant:
+    use: Planet
+    tag: Giant
+    number: 1
+  Loop:
+    levels:
+      - use: Loop
+levels:
+  - use: Planet
+  - use: Planet
+    tag: Rock
+    number: 3
+  - use: Giant
+    levels:
+      - tag: Ring
+        number: 4
+`
+
+func TestPrototype(t *testing.T) {
+	fsys := fstest.MapFS{
+		"prototype.yaml": {Data: []byte(prototypeYaml)},
+		"cycle.yaml":     {Data: []byte(prototypeYaml + "  - use: Loop\n")},
+		"unknown.yaml":   {Data: []byte(prototypeYaml + "  - use: Nothing\n")},
+	}
+
+	lv, err := ReadFromFile("prototype.yaml", ReadFS(fsys), ReadValidate())
+	if err != nil {
+		t.Fatalf("error reading prototype spec: %v", err)
+	}
+	if lv.Prototypes != nil {
+		t.Error("expected prototypes to be removed once resolved")
+	}
+	for i, exp := range []struct {
+		tag    string
+		number int
+		child  string
+	}{
+		{"Planet", 2, "Moon"},
+		{"Rock", 3, "Moon"},
+		{"Giant", 1, "Ring"},
+	} {
+		c := lv.Levels[i]
+		if c.Tag != exp.tag || c.Number != exp.number || len(c.Levels) != 1 || c.Levels[0].Tag != exp.child {
+			t.Errorf("expected %s %d with %s, got %+v", exp.tag, exp.number, exp.child, c)
+		}
+		if c.parent != lv || c.Levels[0].parent != c || c.Levels[0].depth != 3 {
+			t.Errorf("expected %s to be notated as part of the tree", c.Tag)
+		}
+	}
+
+	s := fsSkelington(t, fsys, "testPrototype", "prototype.yaml", "bge")
+	compareStats("prototype", t, s.Report(), map[string]int{
+		"TOTAL": 6,
+		"MOON":  2,
+		"RING":  4,
+	})
+
+	for _, bad := range []string{"cycle.yaml", "unknown.yaml"} {
+		_, err = ReadFromFile(bad, ReadFS(fsys))
+		if err == nil {
+			t.Errorf("expected prototype error reading %s", bad)
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bge.yaml": {Data: []byte(bgeYaml)},
+	}
+	s := fsSkelington(t, fsys, "testQuery", "bge.yaml", "bge")
+	rp := s.Report()
+
+	q := s.Query()
+	if q.Count() != len(s.Has) {
+		t.Errorf("expected query of all %d handles, got %d", len(s.Has), q.Count())
+	}
+	if n := q.Unit("Trees", "Rocks").Count(); n != rp["TREES"]+rp["ROCKS"] {
+		t.Errorf("expected %d trees and rocks, got %d", rp["TREES"]+rp["ROCKS"], n)
+	}
+	if n := q.Root("testQuery").Count(); n != len(s.Has) {
+		t.Errorf("expected %d handles by root, got %d", len(s.Has), n)
+	}
+	if n := q.Root("elsewhere").Count(); n != 0 {
+		t.Errorf("expected no handles by unknown root, got %d", n)
+	}
+
+	star := q.Family("Universe/*/Star/**")
+	if n := star.Count(); n != rp["PLANET"]+rp["CIVILIZATION"]+rp["TREES"]+rp["ROCKS"] {
+		t.Errorf("unexpected count of handles below star: %d", n)
+	}
+	if n := q.Family("**/BlackHole").Count(); n != rp["TYPE1"]+rp["TYPE2"]+rp["TYPE3"]+rp["TYPE4"] {
+		t.Errorf("unexpected count of black hole handles: %d", n)
+	}
+	if n := star.Unit("Planet").Count(); n != rp["PLANET"] {
+		t.Errorf("expected chained query of %d planets, got %d", rp["PLANET"], n)
+	}
+
+	first := q.Unit("Dust").Sequence(1, 5)
+	if first.Count() != 5 {
+		t.Errorf("expected 5 dust by sequence, got %d", first.Count())
+	}
+	for _, h := range first.Handles() {
+		if h.Sequence().Number > 5 {
+			t.Errorf("unexpected sequence in range: %s", h.Sequence())
+		}
+	}
+	if n := q.Where(func(h Handle) bool { return h.Sequence().Count == 2 }).Count(); n != rp["UNIVERSE"] {
+		t.Errorf("unexpected count of handles sequenced of 2: %d", n)
+	}
+
+	var called int
+	SkelingtonHandleCalls(first, func(Handle) error {
+		called++
+		return nil
+	})
+	s.RunHook(HPost)
+	if called != 5 {
+		t.Errorf("expected calls of 5 handles in view, got %d", called)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bge.yaml": {Data: []byte(bgeYaml)},
+	}
+	s := fsSkelington(t, fsys, "testIndex", "bge.yaml", "bge")
+	rp := s.Report()
+
+	for _, h := range s.Has {
+		if f, ok := s.ByKey(h.Key()); !ok || f != h {
+			t.Fatalf("expected handle by key %s", h.Key())
+		}
+	}
+	if n := len(s.ByUnit("Planet")); n != rp["PLANET"] {
+		t.Errorf("expected %d planets by unit, got %d", rp["PLANET"], n)
+	}
+	if n := len(s.ByFamily("Universe/Galaxy/BlackHole")); n != rp["TYPE1"]+rp["TYPE2"]+rp["TYPE3"]+rp["TYPE4"] {
+		t.Errorf("unexpected count of black hole handles by family: %d", n)
+	}
+	for i, h := range s.ByUnit("Dust") {
+		if h.Sequence().Number != i+1 {
+			t.Errorf("expected handles by unit in sequence, got %s at %d", h.Sequence(), i)
+			break
+		}
+	}
+
+	dust := s.ByUnit("Dust")
+	gone := dust[0]
+	s.Remove(dust[:10]...)
+	if _, ok := s.ByKey(gone.Key()); ok {
+		t.Error("expected removed handle to be absent by key")
+	}
+	if n := len(s.ByUnit("Dust")); n != rp["DUST"]-10 {
+		t.Errorf("expected %d dust after removal, got %d", rp["DUST"]-10, n)
+	}
+	if len(s.Has) != rp["TOTAL"]-10 {
+		t.Errorf("expected %d handles after removal, got %d", rp["TOTAL"]-10, len(s.Has))
+	}
+
+	SkelingtonSequence(s)
+	if h := s.ByUnit("Dust")[0]; h.Sequence().Number != 1 || h.Sequence().Count != rp["DUST"]-10 {
+		t.Errorf("expected resequenced dust, got %s", h.Sequence())
+	}
+
+	nh := newHandle(&Sequence{1, 1}, &Tag{0, "testIndex"}, []*Tag{{1, "Added"}}, &Tag{2, "Comet"}, nil)
+	s.Add(nh)
+	if f, ok := s.ByKey(nh.Key()); !ok || f != nh || len(s.ByUnit("Comet")) != 1 || len(s.ByFamily("Added")) != 1 {
+		t.Error("expected added handle to be indexed")
+	}
+
+	s.Has = s.Has[:0]
+	s.Reindex()
+	if _, ok := s.ByKey(nh.Key()); ok {
+		t.Error("expected reindex to follow Has")
+	}
+	s.Add(nh)
+	s.Clear()
+	if len(s.ByUnit("Comet")) != 0 {
+		t.Error("expected clear to empty indexes")
+	}
+
+	fresh, err := New(SetRoot("testIndexFresh"))
+	if err != nil || fresh == nil {
+		t.Fatalf("error with fresh index skeleton instance: %v", err)
+	}
+	fresh.Add(nh)
+	if len(fresh.ByUnit("Comet")) != 1 || len(fresh.ByFamily("Added")) != 1 {
+		t.Errorf("expected handle added to a fresh instance indexed once, got %d", len(fresh.ByUnit("Comet")))
+	}
+}
+
+var attributesYaml = `
+tag: ATTRIBUTES_TEST
+attributes:
+  owner: team-a
+  retention: 30d
+number: 10
+levels:
+  - tag: Car
+    number: 60
+    attributes:
+      color: red
+  - tag: Road
+    number: 40
+    attributes:
+      owner: team-b
+`
+
+// A Handle implemented apart from this package, without attributes.
+type plainHandle struct {
+	Sequencer
+	Tagger
+	Pather
+	Caller
+	Handles
+}
+
+func TestAttributes(t *testing.T) {
+	var plain Handle = plainHandle{}
+	if a := attributesOf(plain); a != nil {
+		t.Errorf("expected no attributes for a Handle that is not an Attributer, got %v", a)
+	}
+
+	fsys := fstest.MapFS{
+		"attributes.yaml":                 {Data: []byte(attributesYaml)},
+		"dir/Car/.attributes.yaml":        {Data: []byte("color: blue\n")},
+		"dir/Car/1-of-2":                  {Mode: fs.ModeDir},
+		"dir/Car/2-of-2":                  {Mode: fs.ModeDir},
+		"dir/Car/2-of-2/.attributes.yaml": {Data: []byte("color: green\n")},
+	}
+
+	for _, a := range []string{"rsp", "bge"} {
+		s := fsSkelington(t, fsys, "testAttributes", "attributes.yaml", a)
+		for _, h := range s.Has {
+			exp := map[string]interface{}{"owner": "team-a", "retention": "30d"}
+			switch h.Unit().Value {
+			case "Car":
+				exp["color"] = "red"
+			case "Road":
+				exp["owner"] = "team-b"
+			}
+			if !reflect.DeepEqual(attributesOf(h), exp) {
+				t.Errorf("%s: expected attributes %v for %s, got %v", a, exp, h.Path(), attributesOf(h))
+			}
+		}
+		if v, ok := s.Has[0].(Attributer).Attr("retention"); !ok || v != "30d" {
+			t.Errorf("%s: expected inherited attribute, got %v", a, v)
+		}
+		if _, ok := s.Has[0].(Attributer).Attr("missing"); ok {
+			t.Errorf("%s: expected missing attribute to be absent", a)
+		}
+
+		b := new(bytes.Buffer)
+		ExportSkelington(b, s)
+		if !strings.Contains(b.String(), "color: red") {
+			t.Errorf("%s: expected exported attributes, got %s", a, b.String())
+		}
+	}
+
+	s, err := New(
+		SetRoot("dir"),
+		SetFS(fsys),
+		SetAllocator("edf"),
+		SetAllocationOffset(DefaultSequencePatternString),
+	)
+	if err != nil || s == nil || len(s.Has) != 2 {
+		t.Fatalf("error with edf attributes skeleton instance: %v", err)
+	}
+	for _, h := range s.Has {
+		if v, _ := h.(Attributer).Attr("color"); v != "blue" {
+			t.Errorf("expected edf attribute blue for %s, got %v", h.Path(), v)
+		}
+	}
+}
+
+type typedItem struct {
+	path  string
+	calls int
+}
+
+func TestTypedItems(t *testing.T) {
+	fsys := fstest.MapFS{
+		"format.yaml": {Data: []byte(formatYaml)},
+	}
+	s, err := NewTyped(
+		func(h Handle) *typedItem {
+			return &typedItem{path: h.Path()}
+		},
+		SetRoot("testTyped"),
+		SetFile("format.yaml"),
+		SetFS(fsys),
+		SetAllocator("lit"),
+	)
+	if err != nil || s == nil {
+		t.Fatalf("error with typed skeleton instance: %v", err)
+	}
+
+	s.HandleCalls(func(h Handle, i *typedItem) error {
+		i.calls++
+		return nil
+	})
+	if err = s.RunHook(HPost); err != nil {
+		t.Errorf("error calling typed handles: %v", err)
+	}
+	var n int
+	for h, i := range s.Items() {
+		n++
+		if i.path != h.Path() || i.calls != 1 {
+			t.Errorf("unexpected typed item for %s: %+v", h.Path(), i)
+		}
+	}
+	if n != len(s.Has) || n != 6 {
+		t.Errorf("expected 6 typed items, got %d", n)
+	}
+
+	h := s.Has[0]
+	if _, ok := ItemOf[string](h); ok {
+		t.Error("expected item not to be a string")
+	}
+	h.SetItem("replaced")
+	if v, ok := ItemOf[string](h); !ok || v != "replaced" {
+		t.Errorf("expected string item, got %v", v)
+	}
+	err = TypedCall(func(Handle, *typedItem) error { return nil })(h)
+	if err == nil {
+		t.Error("expected typed call error for item of another type")
+	}
+}
+
+func TestTree(t *testing.T) {
+	fsys := fstest.MapFS{
+		"format.yaml": {Data: []byte(formatYaml)},
+	}
+	s := fsSkelington(t, fsys, "testTree", "format.yaml", "lit")
+
+	top := s.Tree()
+	if top.Tag != nil || top.Parent() != nil || top.Count() != 6 || len(top.Children()) != 1 {
+		t.Fatalf("unexpected top of tree: %+v", top)
+	}
+	root := top.Children()[0]
+	if root.Tag.Value != "testTree" || len(root.Children()) != 1 {
+		t.Fatalf("unexpected root of tree: %+v", root)
+	}
+	spec := root.Children()[0]
+	if spec.Tag.Value != "FORMAT_TEST" || spec.Count() != 6 || len(spec.Children()) != 2 {
+		t.Fatalf("unexpected spec node: %+v", spec)
+	}
+	car, road := spec.Children()[0], spec.Children()[1]
+	if car.Tag.Value != "Car" || car.Count() != 3 || len(car.Children()) != 3 {
+		t.Errorf("unexpected car node: %+v", car)
+	}
+	if road.Tag.Value != "Road" || road.Count() != 3 || road.Handle != nil {
+		t.Errorf("unexpected road node: %+v", road)
+	}
+	if sb := car.Siblings(); len(sb) != 1 || sb[0] != road {
+		t.Error("expected road as the only sibling of car")
+	}
+	for _, c := range car.Children() {
+		if c.Handle == nil || c.Handle.Unit().Value != "Car" || c.Parent() != car || c.Count() != 1 {
+			t.Errorf("unexpected handle node: %+v", c)
+		}
+		if c.Tag.Value != c.Handle.Sequence().String() {
+			t.Errorf("expected handle node tagged by sequence, got %s", c.Tag.Value)
+		}
+	}
+
+	var depth, breadth []string
+	for n := range spec.DepthFirst() {
+		depth = append(depth, n.Tag.Value)
+	}
+	for n := range spec.BreadthFirst() {
+		breadth = append(breadth, n.Tag.Value)
+	}
+	if exp := []string{
+		"FORMAT_TEST",
+		"Car", "1-of-3", "2-of-3", "3-of-3",
+		"Road", "Curved", "1-of-1", "Straight", "1-of-2", "2-of-2",
+	}; !reflect.DeepEqual(depth, exp) {
+		t.Errorf("expected depth first %v, got %v", exp, depth)
+	}
+	if exp := []string{
+		"FORMAT_TEST",
+		"Car", "Road",
+		"1-of-3", "2-of-3", "3-of-3", "Curved", "Straight",
+		"1-of-1", "1-of-2", "2-of-2",
+	}; !reflect.DeepEqual(breadth, exp) {
+		t.Errorf("expected breadth first %v, got %v", exp, breadth)
+	}
+	for range top.DepthFirst() {
+		break
+	}
+
+	if n := s.Query().Unit("Straight").Tree().Count(); n != 2 {
+		t.Errorf("expected tree of view with 2 handles, got %d", n)
+	}
+}
+
+var diffYaml = `tag: FORMAT_TEST
+levels:
+  - tag: Car
+    number: 4
+  - tag: Road
+    levels:
+      - tag: Straight
+        number: 2
+      - tag: Bridge
+        number: 1`
+
+func TestDiff(t *testing.T) {
+	fsys := fstest.MapFS{
+		"format.yaml": {Data: []byte(formatYaml)},
+		"diff.yaml":   {Data: []byte(diffYaml)},
+	}
+	a := fsSkelington(t, fsys, "testDiff", "format.yaml", "lit")
+	if d := Diff(a, fsSkelington(t, fsys, "testDiff", "format.yaml", "lit")); !d.Empty() {
+		t.Errorf("expected no difference, got %s", d)
+	}
+	if d := Diff(a, fsSkelington(t, fsys, "elsewhere", "format.yaml", "lit")); !d.Empty() {
+		t.Errorf("expected no difference by sequence, got %s", d)
+	}
+
+	root := filepath.Join(t.TempDir(), "testDiffRSP")
+	rsp, err := New(
+		SetRoot(root),
+		SetFile("rsp.yaml"),
+		SetFS(fstest.MapFS{"rsp.yaml": {Data: []byte(rspYaml)}}),
+		SetAllocator("rsp"),
+	)
+	if err != nil || rsp == nil {
+		t.Fatalf("error with rsp diff skeleton instance: %v", err)
+	}
+	if _, err = Materialize(rsp, ""); err != nil {
+		t.Fatalf("error materializing rsp diff skeleton: %v", err)
+	}
+	edf, err := New(
+		SetRoot(root),
+		SetAllocator("edf"),
+		SetAllocationOffset(DefaultSequencePatternString),
+	)
+	if err != nil || edf == nil {
+		t.Fatalf("error with edf diff skeleton instance: %v", err)
+	}
+	if d := Diff(rsp, edf); !d.Empty() {
+		t.Errorf("expected no difference between rsp and edf of its directory, got %s", d)
+	}
+	edf.Remove(edf.ByUnit("Car")[0])
+	if d := Diff(rsp, edf); len(d.Removed) != 1 || len(d.Added) != 0 || d.Tags["TOTAL"] != 0 {
+		t.Errorf("expected one removed handle between rsp and edf, got %s", d)
+	}
+
+	d := Diff(a, fsSkelington(t, fsys, "testDiff", "diff.yaml", "lit"))
+	added := handlePaths(d.Added)
+	sort.Strings(added)
+	if !reflect.DeepEqual(added, []string{
+		"testDiff/FORMAT_TEST/Car/4-of-4",
+		"testDiff/FORMAT_TEST/Road/Bridge/1-of-1",
+	}) {
+		t.Errorf("unexpected added handles: %v", added)
+	}
+	if removed := handlePaths(d.Removed); !reflect.DeepEqual(removed, []string{
+		"testDiff/FORMAT_TEST/Road/Curved/1-of-1",
+	}) {
+		t.Errorf("unexpected removed handles: %v", removed)
+	}
+	if len(d.Resequenced) != 3 {
+		t.Errorf("expected 3 resequenced handles, got %d", len(d.Resequenced))
+	}
+	for _, r := range d.Resequenced {
+		if r.From.Sequence().Count != 3 || r.To.Sequence().Count != 4 || r.From.Sequence().Number != r.To.Sequence().Number {
+			t.Errorf("unexpected resequence %s -> %s", r.From.Path(), r.To.Path())
+		}
+	}
+	if exp := map[string]int{"TOTAL": 1, "CAR": 1, "CURVED": -1, "BRIDGE": 1}; !reflect.DeepEqual(d.Tags, exp) {
+		t.Errorf("expected tag changes %v, got %v", exp, d.Tags)
+	}
+
+	txt := d.String()
+	for _, line := range []string{
+		"+ testDiff/FORMAT_TEST/Car/4-of-4\n",
+		"- testDiff/FORMAT_TEST/Road/Curved/1-of-1\n",
+		"~ testDiff/FORMAT_TEST/Car/1-of-3 -> testDiff/FORMAT_TEST/Car/1-of-4\n",
+		"CURVED -1\n",
+		"TOTAL +1\n",
+	} {
+		if !strings.Contains(txt, line) {
+			t.Errorf("expected %q in difference:\n%s", line, txt)
+		}
+	}
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("error rendering difference as json: %v", err)
+	}
+	var jd struct {
+		Added       []string
+		Removed     []string
+		Resequenced []struct{ From, To string }
+		Tags        map[string]int
+	}
+	if err = json.Unmarshal(b, &jd); err != nil || len(jd.Added) != 2 || len(jd.Removed) != 1 || len(jd.Resequenced) != 3 || jd.Tags["CAR"] != 1 {
+		t.Errorf("unexpected json difference %s: %v", b, err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	fsys := fstest.MapFS{
+		"format.yaml": {Data: []byte(formatYaml)},
+		"diff.yaml":   {Data: []byte(diffYaml)},
+	}
+	var after int
+	cnf := []Config{
+		SetHook(HAfter, func(*Skelington) error {
+			after++
+			return nil
+		}),
+		SetStat("after", func(s *Skelington, m map[string]int) error {
+			m["MERGED"] = m["MERGED"] + 1
+			return nil
+		}),
+	}
+	a := fsSkelington(t, fsys, "testMerge", "format.yaml", "lit", cnf...)
+	b := fsSkelington(t, fsys, "testMerge", "diff.yaml", "lit", cnf...)
+	a.ByUnit("Car")[0].SetItem("item")
+
+	after = 0
+	m, err := Merge(a, b)
+	if err != nil {
+		t.Fatalf("error merging: %v", err)
+	}
+	compareStats("merge", t, m.Report(), map[string]int{
+		"TOTAL":    11,
+		"CAR":      7,
+		"STRAIGHT": 2,
+		"CURVED":   1,
+		"BRIDGE":   1,
+		"MERGED":   2,
+	})
+	if after != 2 {
+		t.Errorf("expected merged hooks of both instances, ran %d", after)
+	}
+	for _, h := range m.ByUnit("Car") {
+		if h.Sequence().Count != 7 {
+			t.Errorf("expected cars resequenced across merge, got %s", h.Sequence())
+		}
+	}
+	if v, ok := m.ByUnit("Car")[0].Item().(string); !ok || v != "item" {
+		t.Errorf("expected merged handle to keep its item, got %v", v)
+	}
+	if m.ByUnit("Car")[0] == a.ByUnit("Car")[0] || a.ByUnit("Car")[0].Sequence().Count != 3 {
+		t.Error("expected merged handles to be cloned")
+	}
+
+	m, err = MergeWith(DuplicateKeepBoth, a, b)
+	if err != nil || len(m.Has) != 13 || len(m.ByUnit("Straight")) != 4 {
+		t.Errorf("unexpected merge keeping both: %v", err)
+	}
+	for _, h := range m.ByUnit("Straight") {
+		if h.Sequence().Count != 4 {
+			t.Errorf("expected straights resequenced across merge, got %s", h.Sequence())
+		}
+	}
+
+	if _, err = MergeWith(DuplicateFail, a, b); err == nil {
+		t.Error("expected error merging duplicate paths")
+	}
+	if err = m.Reallocate(); err == nil {
+		t.Error("expected error reallocating merged instance")
+	}
+}
+
+type snapshotItem struct {
+	Name  string
+	Count int
+}
+
+func TestSnapshot(t *testing.T) {
+	gob.Register(&snapshotItem{})
+	fsys := fstest.MapFS{
+		"attributes.yaml": {Data: []byte(strings.Replace(attributesYaml, "retention: 30d", "retention: 30d\n  days: 30\n  ratio: 0.5\n  tiers: [1, 2.5]", 1))},
+	}
+	s := fsSkelington(t, fsys, "testSnapshot", "attributes.yaml", "rsp",
+		SetItemFactory(func(h Handle) interface{} {
+			return &snapshotItem{h.Unit().Value, h.Sequence().Number}
+		}),
+	)
+
+	same := func(kind string, l *Skelington) {
+		if len(l.Has) != len(s.Has) {
+			t.Fatalf("%s: expected %d loaded handles, got %d", kind, len(s.Has), len(l.Has))
+		}
+		for i, h := range s.Has {
+			lh := l.Has[i]
+			if lh.Key() != h.Key() || lh.Path() != h.Path() || !reflect.DeepEqual(attributesOf(lh), attributesOf(h)) {
+				t.Errorf("%s: expected %s %s, loaded %s %s", kind, h.Key(), h.Path(), lh.Key(), lh.Path())
+			}
+			if f, ok := l.ByKey(h.Key()); !ok || f != lh {
+				t.Errorf("%s: expected loaded handle indexed by key %s", kind, h.Key())
+			}
+		}
+		if !reflect.DeepEqual(l.Report(), s.Report()) {
+			t.Errorf("%s: expected report %v, loaded %v", kind, s.Report(), l.Report())
+		}
+	}
+
+	b := new(bytes.Buffer)
+	if err := s.Save(b); err != nil {
+		t.Fatalf("error saving json snapshot: %v", err)
+	}
+	l, err := Load(b)
+	if err != nil {
+		t.Fatalf("error loading json snapshot: %v", err)
+	}
+	same("json", l)
+	if v, _ := l.Has[0].(Attributer).Attr("days"); v != 30 {
+		t.Errorf("expected numeric attribute loaded as int, got %T %v", v, v)
+	}
+	total, unit := l.Report()["TOTAL"], strings.ToUpper(l.Has[0].Unit().Value)
+	count := l.Report()[unit]
+	l.Add(newHandle(&Sequence{1, 1}, l.Has[0].Root(), l.Has[0].Family(), l.Has[0].Unit(), nil))
+	if l.Report()["TOTAL"] != total || l.Report()[unit] != count {
+		t.Errorf("expected statistics of a loaded instance finished, got TOTAL %d %s %d", l.Report()["TOTAL"], unit, l.Report()[unit])
+	}
+	if item, ok := l.Has[0].Item().(map[string]interface{}); !ok || item["Name"] != s.Has[0].Unit().Value {
+		t.Errorf("expected json item, loaded %v", l.Has[0].Item())
+	}
+
+	b.Reset()
+	if err = s.SaveGob(b); err != nil {
+		t.Fatalf("error saving gob snapshot: %v", err)
+	}
+	l, err = Load(b)
+	if err != nil {
+		t.Fatalf("error loading gob snapshot: %v", err)
+	}
+	same("gob", l)
+	if item, ok := ItemOf[*snapshotItem](l.Has[0]); !ok || *item != *s.Has[0].Item().(*snapshotItem) {
+		t.Errorf("expected gob item, loaded %v", l.Has[0].Item())
+	}
+
+	u := fsSkelington(t, fsys, "testSnapshot", "attributes.yaml", "rsp",
+		SetSequenceFormat(UnderscoreSequence),
+	)
+	for _, save := range []func(io.Writer) error{u.Save, u.SaveGob} {
+		b.Reset()
+		if err = save(b); err != nil {
+			t.Fatalf("error saving underscore snapshot: %v", err)
+		}
+		l, err = Load(b)
+		if err != nil {
+			t.Fatalf("error loading underscore snapshot: %v", err)
+		}
+		for i, h := range u.Has {
+			if l.Has[i].Path() != h.Path() || !strings.Contains(h.Path(), "_") {
+				t.Errorf("expected underscore sequence %s, loaded %s", h.Path(), l.Has[i].Path())
+			}
+		}
+	}
+
+	s.Has[0].SetItem(func() {})
+	if err = s.Save(new(bytes.Buffer)); err == nil {
+		t.Error("expected error saving an item unable to be marshalled")
+	}
+	if _, err = Load(strings.NewReader("not a snapshot")); err == nil {
+		t.Error("expected error loading an invalid snapshot")
+	}
+}
+
+func TestSequenceFormat(t *testing.T) {
+	for _, tc := range []struct {
+		key string
+		seq Sequence
+		exp string
+	}{
+		{"default", Sequence{7, 100}, "7-of-100"},
+		{"padded", Sequence{7, 100}, "007-of-100"},
+		{"underscore", Sequence{7, 100}, "7_100"},
+		{"number", Sequence{7, 0}, "7"},
+	} {
+		f, err := ParseSequenceFormat(tc.key)
+		if err != nil {
+			t.Fatalf("error parsing sequence format %s: %v", tc.key, err)
+		}
+		if got := f.Format(&tc.seq); got != tc.exp {
+			t.Errorf("%s: expected %s, got %s", tc.key, tc.exp, got)
+		}
+		p, err := f.Parse(tc.exp)
+		if err != nil || *p != tc.seq {
+			t.Errorf("%s: expected to parse %s as %v, got %v: %v", tc.key, tc.exp, tc.seq, p, err)
+		}
+		if !regexp.MustCompile(f.Pattern()).MatchString(tc.exp) {
+			t.Errorf("%s: expected pattern %s to match %s", tc.key, f.Pattern(), tc.exp)
+		}
+	}
+	if _, err := ParseSequenceFormat("roman"); err == nil {
+		t.Error("expected error parsing unknown sequence format")
+	}
+	if _, err := UnderscoreSequence.Parse("7-of-100"); err == nil {
+		t.Error("expected error parsing sequence of another format")
+	}
+
+	tmpDir := t.TempDir()
+	fsys := fstest.MapFS{
+		"padded.yaml": {Data: []byte("tag: PADDED_TEST\nlevels:\n  - tag: Car\n    number: 12\n  - tag: Type1\n    number: 1")},
+	}
+	root := filepath.Join(tmpDir, "padded")
+	s := fsSkelington(t, fsys, root, "padded.yaml", "lit",
+		SetSequenceFormat(PaddedSequence),
+	)
+	for _, h := range s.ByUnit("Car") {
+		if exp := PaddedSequence.Format(h.Sequence()); filepath.Base(h.Path()) != exp {
+			t.Errorf("expected padded path ending %s, got %s", exp, h.Path())
+		}
+	}
+	if (&Sequence{1, 12}).String() != "1-of-12" {
+		t.Error("expected the default sequence string to be unchanged")
+	}
+	if _, err := Materialize(s, ""); err != nil {
+		t.Fatalf("error materializing padded skeleton: %v", err)
+	}
+
+	r, err := New(
+		SetRoot(root),
+		SetAllocator("edf"),
+		SetSequenceFormat(PaddedSequence),
+	)
+	if err != nil || r == nil {
+		t.Fatalf("error with padded edf skeleton instance: %v", err)
+	}
+	compareStats("padded", t, r.Report(), map[string]int{
+		"TOTAL": 13,
+		"CAR":   12,
+		"TYPE1": 1,
+	})
+	if !strings.HasSuffix(r.ByUnit("Car")[0].Path(), "PADDED_TEST/Car/01-of-12") {
+		t.Errorf("expected edf paths as allocated, got %s", r.ByUnit("Car")[0].Path())
+	}
 }
-*/
@@ -0,0 +1,177 @@
+package skelington
+
+import (
+	"bufio"
+	"encoding/gob"
+	"encoding/json"
+	"io"
+	"strconv"
+	"unicode"
+
+	"github.com/Laughs-In-Flowers/xrr"
+)
+
+var SnapshotError = xrr.Xrror("unable to snapshot handle %s: %s").Out
+
+type snapshotHandle struct {
+	Key        string                 `json:"key"`
+	Root       *Tag                   `json:"root"`
+	Family     []*Tag                 `json:"family"`
+	Unit       *Tag                   `json:"unit"`
+	Sequence   *Sequence              `json:"sequence"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Item       interface{}            `json:"item,omitempty"`
+	Format     string                 `json:"format,omitempty"`
+}
+
+type snapshot struct {
+	Handles []snapshotHandle `json:"handles"`
+	Report  map[string]int   `json:"report"`
+}
+
+func init() {
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
+
+func (s *Skelington) snapshot() *snapshot {
+	ret := &snapshot{
+		Handles: make([]snapshotHandle, 0, len(s.Has)),
+		Report:  s.Report(),
+	}
+	for _, h := range s.Has {
+		var format string
+		if fh, ok := h.(*handle); ok && fh.format != nil {
+			format = fh.format.String()
+		}
+		ret.Handles = append(ret.Handles, snapshotHandle{
+			h.Key(),
+			h.Root(),
+			h.Family(),
+			h.Unit(),
+			h.Sequence(),
+			attributesOf(h),
+			h.Item(),
+			format,
+		})
+	}
+	return ret
+}
+
+// Writes a json snapshot of the Skelington instance to the provided io.Writer,
+// with every handle key, tags, sequence, attributes and item, and the statistics
+// report. Every item must be able to be marshalled to json.
+func (s *Skelington) Save(w io.Writer) error {
+	sn := s.snapshot()
+	for _, h := range sn.Handles {
+		if _, err := json.Marshal(h.Item); err != nil {
+			return SnapshotError(h.Key, err)
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sn)
+}
+
+// Writes a gob snapshot of the Skelington instance to the provided io.Writer, as
+// Save. The concrete type of every item must be registered with gob.Register.
+func (s *Skelington) SaveGob(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(s.snapshot())
+}
+
+// Reads a json or gob snapshot written by Save or SaveGob from the provided
+// io.Reader, returning a new Skelington instance with handles, their sequence
+// format, and statistics as saved. Any SequenceFormat other than those provided
+// by this package is unable to be loaded. Attributes and items of a json
+// snapshot are as unmarshalled to an interface{} by encoding/json, except that
+// numbers are an int when whole and a float64 otherwise. The Skelington instance
+// cannot be reallocated.
+func Load(r io.Reader) (*Skelington, error) {
+	br := bufio.NewReader(r)
+	sn := &snapshot{}
+	var err error
+	switch {
+	case isJSON(br):
+		dec := json.NewDecoder(br)
+		dec.UseNumber()
+		if err = dec.Decode(sn); err == nil {
+			for i := range sn.Handles {
+				sh := &sn.Handles[i]
+				for k, v := range sh.Attributes {
+					sh.Attributes[k] = number(v)
+				}
+				sh.Item = number(sh.Item)
+			}
+		}
+	default:
+		err = gob.NewDecoder(br).Decode(sn)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s := newSkelington(nil)
+	var add []Handle
+	for _, sh := range sn.Handles {
+		h := newHandle(sh.Sequence, sh.Root, sh.Family, sh.Unit, sh.Attributes)
+		h.id = sh.Key
+		h.item = sh.Item
+		if sh.Format != "" {
+			if h.format, err = ParseSequenceFormat(sh.Format); err != nil {
+				return nil, err
+			}
+		}
+		add = append(add, h)
+	}
+	if err = s.RunHook(HBefore); err != nil {
+		return nil, err
+	}
+	if err = s.Add(add...); err != nil {
+		return nil, err
+	}
+	if err = s.RunHook(HAfter); err != nil {
+		return nil, err
+	}
+	for k, v := range sn.Report {
+		s.Report()[k] = v
+	}
+	return s, nil
+}
+
+// Converts any json.Number within the provided value, as decoded by encoding/json,
+// to an int when whole and a float64 otherwise.
+func number(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := strconv.Atoi(t.String()); err == nil {
+			return i
+		}
+		f, _ := t.Float64()
+		return f
+	case map[string]interface{}:
+		for k, vv := range t {
+			t[k] = number(vv)
+		}
+	case []interface{}:
+		for i, vv := range t {
+			t[i] = number(vv)
+		}
+	}
+	return v
+}
+
+func isJSON(br *bufio.Reader) bool {
+	for n := 1; n <= br.Size(); n++ {
+		b, err := br.Peek(n)
+		if len(b) < n {
+			return false
+		}
+		if c := b[n-1]; !unicode.IsSpace(rune(c)) {
+			return c == '{'
+		}
+		if err != nil {
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,258 @@
+package skelington
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Laughs-In-Flowers/xrr"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// A type for specifying the format of a Level specification.
+type SpecFormat int
+
+const (
+	AutoFormat SpecFormat = iota // detect by extension, then by content
+	YAMLFormat
+	JSONFormat
+	TOMLFormat
+)
+
+// The string value of the SpecFormat.
+func (f SpecFormat) String() string {
+	switch f {
+	case YAMLFormat:
+		return "yaml"
+	case JSONFormat:
+		return "json"
+	case TOMLFormat:
+		return "toml"
+	}
+	return "auto"
+}
+
+var SpecFormatError = xrr.Xrror("unknown specification format %s").Out
+
+// Provided a string key, one of 'auto', 'yaml', 'yml', 'json' or 'toml', returns
+// the corresponding SpecFormat and any error.
+func ParseSpecFormat(k string) (SpecFormat, error) {
+	switch strings.ToLower(k) {
+	case "", "auto":
+		return AutoFormat, nil
+	case "yaml", "yml":
+		return YAMLFormat, nil
+	case "json":
+		return JSONFormat, nil
+	case "toml":
+		return TOMLFormat, nil
+	}
+	return AutoFormat, SpecFormatError(k)
+}
+
+// A function configuring how a Level specification is read.
+type ReadOption func(*reading)
+
+type reading struct {
+	ctx       context.Context
+	format    SpecFormat
+	fsys      fs.FS
+	raw       []byte
+	validate  bool
+	variables map[string]string
+	sequence  SequenceFormat
+}
+
+func newReading(opts ...ReadOption) *reading {
+	rd := &reading{ctx: context.Background()}
+	for _, fn := range opts {
+		fn(rd)
+	}
+	return rd
+}
+
+// Provides a context to reading, stopping any directory walk when done.
+func ReadContext(ctx context.Context) ReadOption {
+	return func(rd *reading) {
+		if ctx != nil {
+			rd.ctx = ctx
+		}
+	}
+}
+
+// Forces the provided SpecFormat when reading, rather than detecting it.
+func ReadFormat(f SpecFormat) ReadOption {
+	return func(rd *reading) {
+		rd.format = f
+	}
+}
+
+// Reads any specification file or directory from the provided file system,
+// rather than the operating system.
+func ReadFS(fsys fs.FS) ReadOption {
+	return func(rd *reading) {
+		rd.fsys = fsys
+	}
+}
+
+// Validates the specification after reading, returning any SpecErrors.
+func ReadValidate() ReadOption {
+	return func(rd *reading) {
+		rd.validate = true
+	}
+}
+
+func readRaw(raw []byte) ReadOption {
+	return func(rd *reading) {
+		rd.raw = raw
+	}
+}
+
+func (rd *reading) read(path string) ([]byte, error) {
+	switch {
+	case rd.raw != nil:
+		return rd.raw, nil
+	case rd.fsys != nil:
+		return fs.ReadFile(rd.fsys, path)
+	}
+	f, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	b := bytes.NewBuffer(make([]byte, 0, bytes.MinRead))
+	_, err = b.ReadFrom(f)
+	return b.Bytes(), err
+}
+
+func (rd *reading) detect(path string, raw []byte) SpecFormat {
+	if rd.format != AutoFormat {
+		return rd.format
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return YAMLFormat
+	case ".json":
+		return JSONFormat
+	case ".toml", ".tml":
+		return TOMLFormat
+	}
+	return sniff(raw)
+}
+
+var tomlLine = regexp.MustCompile(`^(\[\[?[A-Za-z0-9_."' -]+\]\]?|[A-Za-z0-9_"'.-]+\s*=.*)$`)
+
+func sniff(raw []byte) SpecFormat {
+	t := bytes.TrimSpace(raw)
+	if len(t) > 0 && (t[0] == '{' || t[0] == '[') && json.Valid(t) {
+		return JSONFormat
+	}
+	sc := bufio.NewScanner(bytes.NewReader(t))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if tomlLine.MatchString(line) {
+			return TOMLFormat
+		}
+		break
+	}
+	return YAMLFormat
+}
+
+func decode(f SpecFormat, raw []byte, lv *Level) error {
+	switch f {
+	case JSONFormat:
+		return json.Unmarshal(raw, lv)
+	case TOMLFormat:
+		return toml.Unmarshal(raw, lv)
+	}
+	var n yaml.Node
+	err := yaml.Unmarshal(raw, &n)
+	if err != nil || n.Kind == 0 {
+		return err
+	}
+	err = n.Decode(lv)
+	if err != nil {
+		return err
+	}
+	locate(&n, lv)
+	return nil
+}
+
+// Records the line and column of the Level, and of each key of the Level, from
+// the yaml node it was decoded from.
+func locate(n *yaml.Node, lv *Level) {
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	lv.at = position{n.Line, n.Column}
+	lv.keys = make(map[string]position)
+	for i := 0; i+1 < len(n.Content); i = i + 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		lv.keys[k.Value] = position{k.Line, k.Column}
+		if k.Value == "levels" && v.Kind == yaml.SequenceNode {
+			for j, c := range v.Content {
+				if j < len(lv.Levels) {
+					locate(c, lv.Levels[j])
+				}
+			}
+		}
+		if k.Value == "prototypes" && v.Kind == yaml.MappingNode {
+			for j := 0; j+1 < len(v.Content); j = j + 2 {
+				if pl := lv.Prototypes[v.Content[j].Value]; pl != nil {
+					locate(v.Content[j+1], pl)
+				}
+			}
+		}
+	}
+}
+
+func (rd *reading) document(path string, raw []byte) (*Level, error) {
+	f, raw, err := rd.render(path, raw)
+	if err != nil {
+		return nil, err
+	}
+	lv := &Level{}
+	err = decode(f, raw, lv)
+	if err != nil {
+		return nil, err
+	}
+	lv.Iter(func(l *Level) {
+		l.file = path
+	})
+	for _, pl := range lv.Prototypes {
+		if pl != nil {
+			pl.Iter(func(l *Level) {
+				l.file = path
+			})
+		}
+	}
+	return lv, nil
+}
+
+func (rd *reading) level(path string, raw []byte) (*Level, error) {
+	lv, err := rd.document(path, raw)
+	if err != nil {
+		return nil, err
+	}
+	lv, err = rd.resolve(lv, lv, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	lv.Prototypes = nil
+	notate(lv, lv.Levels, 0)
+	if rd.validate {
+		if err = Validate(lv); err != nil {
+			return nil, err
+		}
+	}
+	return lv, nil
+}
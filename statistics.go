@@ -14,6 +14,10 @@ type Statistic interface {
 	Reset()
 }
 
+// The statistics key reporting the seed used by any stochastic allocator, apart
+// from the count of any tag.
+const SeedStatistic = "@SEED"
+
 // A statistics function taking a map of string key to int values.
 type StatFunc func(*Skelington, map[string]int) error
 
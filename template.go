@@ -0,0 +1,98 @@
+package skelington
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Laughs-In-Flowers/xrr"
+	yaml "gopkg.in/yaml.v3"
+)
+
+var TemplateError = xrr.Xrror("undefined variables %s in %s").Out
+
+var placeholder = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_.-]*)\}`)
+
+// Provides variables for rendering specification placeholders, e.g. '${cars}'.
+// Variables provided take precedence over environment variables, which take
+// precedence over those of a 'defaults' block within the specification itself.
+// A placeholder may be escaped as '$${cars}'.
+func ReadVariables(v map[string]string) ReadOption {
+	return func(rd *reading) {
+		if rd.variables == nil {
+			rd.variables = make(map[string]string)
+		}
+		for k, vv := range v {
+			rd.variables[k] = vv
+		}
+	}
+}
+
+type defaulted struct {
+	Defaults map[string]interface{} `yaml:"defaults" json:"defaults" toml:"defaults"`
+}
+
+func defaults(f SpecFormat, raw []byte) map[string]string {
+	var d defaulted
+	switch f {
+	case JSONFormat:
+		json.Unmarshal(raw, &d)
+	case TOMLFormat:
+		toml.Unmarshal(raw, &d)
+	default:
+		yaml.Unmarshal(raw, &d)
+	}
+	ret := make(map[string]string)
+	for k, v := range d.Defaults {
+		ret[k] = fmt.Sprint(v)
+	}
+	return ret
+}
+
+func (rd *reading) lookup(name string, d map[string]string) (string, bool) {
+	if v, ok := rd.variables[name]; ok {
+		return v, true
+	}
+	if v, ok := os.LookupEnv(name); ok {
+		return v, true
+	}
+	v, ok := d[name]
+	return v, ok
+}
+
+// Detects the format of, and renders any placeholders within, the provided raw
+// specification.
+func (rd *reading) render(path string, raw []byte) (SpecFormat, []byte, error) {
+	if !placeholder.Match(raw) {
+		return rd.detect(path, raw), raw, nil
+	}
+
+	zeroed := placeholder.ReplaceAllFunc(raw, func(m []byte) []byte {
+		if m[1] == '$' {
+			return m
+		}
+		return []byte("0")
+	})
+	f := rd.detect(path, zeroed)
+	d := defaults(f, zeroed)
+
+	var missing []string
+	rendered := placeholder.ReplaceAllFunc(raw, func(m []byte) []byte {
+		if m[1] == '$' {
+			return m[1:]
+		}
+		name := string(placeholder.FindSubmatch(m)[1])
+		if v, ok := rd.lookup(name, d); ok {
+			return []byte(v)
+		}
+		missing = append(missing, name)
+		return m
+	})
+	if len(missing) > 0 {
+		return f, nil, TemplateError(strings.Join(missing, ", "), path)
+	}
+	return f, rendered, nil
+}
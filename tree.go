@@ -0,0 +1,148 @@
+package skelington
+
+import (
+	"iter"
+	"sort"
+)
+
+// A node of a tree of handles, gathered by root, family and unit tags with each
+// handle below its unit. A handle node holds its Handle, and any other node a
+// nil Handle.
+type Node struct {
+	Tag      *Tag
+	Handle   Handle
+	parent   *Node
+	children []*Node
+	index    map[string]*Node
+	count    int
+}
+
+func newNode(t *Tag, h Handle, parent *Node) *Node {
+	return &Node{t, h, parent, nil, nil, 0}
+}
+
+// Returns a tree of every handle of the Skelington instance. The returned node
+// has a nil Tag, and holds a node for each root tag. Children are ordered with
+// handles first by sequence number, then by tag order and value.
+func (s *Skelington) Tree() *Node {
+	return tree(s.Has)
+}
+
+// Returns a tree of every handle of the View, as Skelington Tree.
+func (v *View) Tree() *Node {
+	return tree(v.has)
+}
+
+func tree(hs []Handle) *Node {
+	top := newNode(nil, nil, nil)
+	for _, h := range hs {
+		n := top.child(h.Root())
+		tags := append(TagSort{}, h.Family()...)
+		tags.Sort()
+		for _, t := range tags {
+			if t.Value != "" {
+				n = n.child(t)
+			}
+		}
+		n = n.child(h.Unit())
+		hn := newNode(&Tag{h.Unit().Order + 1, formatSequence(h)}, h, n)
+		n.children = append(n.children, hn)
+		for c := hn; c != nil; c = c.parent {
+			c.count = c.count + 1
+		}
+	}
+	for n := range top.DepthFirst() {
+		sort.SliceStable(n.children, n.less)
+	}
+	return top
+}
+
+func (n *Node) less(i, j int) bool {
+	a, b := n.children[i], n.children[j]
+	switch {
+	case a.Handle != nil && b.Handle != nil:
+		sa, sb := a.Handle.Sequence(), b.Handle.Sequence()
+		return sa != nil && sb != nil && sa.Number < sb.Number
+	case a.Handle != nil || b.Handle != nil:
+		return a.Handle != nil
+	case a.Tag.Order != b.Tag.Order:
+		return a.Tag.Order < b.Tag.Order
+	}
+	return a.Tag.Value < b.Tag.Value
+}
+
+func (n *Node) child(t *Tag) *Node {
+	if n.index == nil {
+		n.index = make(map[string]*Node)
+	}
+	if c, ok := n.index[t.Value]; ok {
+		return c
+	}
+	c := newNode(t, nil, n)
+	n.index[t.Value] = c
+	n.children = append(n.children, c)
+	return c
+}
+
+// Returns the parent of the Node, or nil for the top of the tree.
+func (n *Node) Parent() *Node {
+	return n.parent
+}
+
+// Returns the children of the Node.
+func (n *Node) Children() []*Node {
+	return n.children
+}
+
+// Returns every other child of the Node parent.
+func (n *Node) Siblings() []*Node {
+	var ret []*Node
+	if n.parent == nil {
+		return ret
+	}
+	for _, c := range n.parent.children {
+		if c != n {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// Returns the number of handles at and below the Node.
+func (n *Node) Count() int {
+	return n.count
+}
+
+// Yields the Node and every Node below it, depth first.
+func (n *Node) DepthFirst() iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		n.depthFirst(yield)
+	}
+}
+
+func (n *Node) depthFirst(yield func(*Node) bool) bool {
+	if !yield(n) {
+		return false
+	}
+	for _, c := range n.children {
+		if !c.depthFirst(yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// Yields the Node and every Node below it, breadth first.
+func (n *Node) BreadthFirst() iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		queue := []*Node{n}
+		for len(queue) > 0 {
+			c := queue[0]
+			queue = queue[1:]
+			if !yield(c) {
+				return
+			}
+			queue = append(queue, c.children...)
+		}
+	}
+}
@@ -25,9 +25,12 @@ type ErrorHandler func(error)
 
 var openError = xrr.Xrror("unable to find or open file %s, provided %s").Out
 
+// The permission bits used for any directory created by this package.
+var DefaultDirMode os.FileMode = 0755
+
 func exist(path string) {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		os.MkdirAll(path, os.ModeDir|0755)
+		os.MkdirAll(path, os.ModeDir|DefaultDirMode)
 	}
 }
 
@@ -0,0 +1,163 @@
+package skelington
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+type position struct {
+	line, column int
+}
+
+// An error describing a single problem with a Level specification, positioned by
+// file, line and column where known, and by the tag path of the Level.
+type SpecError struct {
+	File   string
+	Line   int
+	Column int
+	Path   string
+	Msg    string
+}
+
+// The string value of the SpecError.
+func (e *SpecError) Error() string {
+	var at []string
+	if e.File != "" {
+		at = append(at, e.File)
+	}
+	if e.Line > 0 {
+		at = append(at, fmt.Sprintf("%d:%d", e.Line, e.Column))
+	}
+	if e.Path != "" {
+		at = append(at, e.Path)
+	}
+	if len(at) == 0 {
+		return e.Msg
+	}
+	return fmt.Sprintf("%s: %s", strings.Join(at, ":"), e.Msg)
+}
+
+// An array of SpecError, in the order encountered.
+type SpecErrors []*SpecError
+
+// The string value of all SpecError, one per line.
+func (e SpecErrors) Error() string {
+	var ret []string
+	for _, v := range e {
+		ret = append(ret, v.Error())
+	}
+	return strings.Join(ret, "\n")
+}
+
+// Returns all underlying errors.
+func (e SpecErrors) Unwrap() []error {
+	var ret []error
+	for _, v := range e {
+		ret = append(ret, v)
+	}
+	return ret
+}
+
+func (e *SpecErrors) add(lv *Level, key string, format string, a ...interface{}) {
+	at := lv.at
+	if p, ok := lv.keys[key]; ok {
+		at = p
+	}
+	*e = append(*e, &SpecError{
+		File:   lv.file,
+		Line:   at.line,
+		Column: at.column,
+		Path:   levelPath(lv),
+		Msg:    fmt.Sprintf(format, a...),
+	})
+}
+
+// Validates the provided Level and every Level below it, returning every problem
+// found as SpecErrors ordered by position, or nil.
+func Validate(lv *Level) error {
+	var errs SpecErrors
+	lv.Iter(func(l *Level) {
+		validate(l, &errs)
+	})
+	sort.SliceStable(errs, func(i, j int) bool {
+		a, b := errs[i], errs[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Reads the specification at the provided path and validates it, returning any
+// read error or SpecErrors.
+func ValidateFile(path string, opts ...ReadOption) error {
+	if rd := newReading(opts...); rd.fsys == nil && rd.raw == nil {
+		if _, err := os.Stat(path); err != nil {
+			return err
+		}
+	}
+	lv, err := ReadFromFile(path, opts...)
+	if err != nil {
+		return err
+	}
+	return Validate(lv)
+}
+
+func validate(lv *Level, errs *SpecErrors) {
+	if lv.parent != nil && lv.Tag == "" {
+		errs.add(lv, "tag", "missing tag")
+	}
+	if lv.Number < 0 {
+		errs.add(lv, "number", "negative number %d", lv.Number)
+	}
+	if lv.Leaf && lv.Number == 0 {
+		errs.add(lv, "leaf", "leaf with no number")
+	}
+	if lv.Min < 0 {
+		errs.add(lv, "min", "negative min %d", lv.Min)
+	}
+	if lv.Max < 0 {
+		errs.add(lv, "max", "negative max %d", lv.Max)
+	}
+	if lv.Max > 0 && lv.Min > lv.Max {
+		errs.add(lv, "min", "min %d exceeds max %d", lv.Min, lv.Max)
+	}
+	if lv.Exact != nil {
+		e := *lv.Exact
+		switch {
+		case e < 0:
+			errs.add(lv, "exact", "negative exact %d", e)
+		case e < lv.Min:
+			errs.add(lv, "exact", "exact %d below min %d", e, lv.Min)
+		case lv.Max > 0 && e > lv.Max:
+			errs.add(lv, "exact", "exact %d above max %d", e, lv.Max)
+		}
+	}
+	if _, err := ParseRounding(lv.Rounding); err != nil {
+		errs.add(lv, "rounding", "%s", err)
+	}
+
+	var relative int
+	seen := make(map[string]bool)
+	for _, c := range lv.Levels {
+		if c.Relative {
+			relative = relative + c.Number
+		}
+		if c.Tag != "" && seen[c.Tag] {
+			errs.add(c, "tag", "duplicate sibling tag %s", c.Tag)
+		}
+		seen[c.Tag] = true
+	}
+	if relative > 100 {
+		errs.add(lv, "levels", "relative numbers of children sum to %d, over 100", relative)
+	}
+}